@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"text/template"
 
 	"go.uber.org/zap"
+	"golang.org/x/term"
 
+	"github.com/jamillosantos/lovr/internal/deadletter"
 	"github.com/jamillosantos/lovr/internal/logctx"
 	"github.com/jamillosantos/lovr/internal/service"
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+	"github.com/jamillosantos/lovr/internal/service/processors"
 )
 
 func runFetcher(ctx context.Context, entriesFetcher *service.EntriesReader, processorsList []service.EntryProcessor) {
@@ -19,19 +24,266 @@ func runFetcher(ctx context.Context, entriesFetcher *service.EntriesReader, proc
 	case errors.Is(err, context.Canceled):
 		return
 	case errors.Is(err, io.EOF):
-		fmt.Println("EOF")
+		// On stderr, so it does not end up in the output piped elsewhere.
+		fmt.Fprintln(os.Stderr, "EOF")
 		return
 	case err != nil:
 		reportFatalError(err)
 	}
 }
 
+// newOutput builds the processor printing the entries: the --output encoder
+// when given, the terminal output otherwise. The returned function ends the
+// output once the input is over.
+func newOutput() (service.EntryProcessor, func()) {
+	if outputArg == "" {
+		return newStdout(), func() {}
+	}
+	format, err := processors.ParseOutputFormat(outputArg)
+	if err != nil {
+		reportFatalError(err)
+	}
+	var opts []processors.OutputOption
+	if len(columnsArg) > 0 {
+		opts = append(opts, processors.WithColumns(columnsArg))
+	}
+	output := processors.NewOutput(format, opts...)
+	return output, func() {
+		if err := output.Close(); err != nil {
+			reportFatalError(err)
+		}
+	}
+}
+
+func newStdout() *processors.Stdout {
+	format, err := processors.ParseStdoutFormat(formatArg)
+	if err != nil {
+		reportFatalError(err)
+	}
+	opts := []processors.StdoutOption{
+		processors.WithFormat(format),
+		processors.WithTimeFormat(timeFormatArg),
+		processors.WithFoldFrames(foldFramesArg),
+	}
+	if len(fieldsArg) > 0 {
+		opts = append(opts, processors.WithFields(fieldsArg))
+	}
+	if tmpl := newTemplate(); tmpl != nil {
+		opts = append(opts, processors.WithTemplate(tmpl))
+	}
+	// Compact lines are cut to the terminal width; pipes get them whole.
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		opts = append(opts, processors.WithWidth(width))
+	}
+	return processors.NewStdout(opts...)
+}
+
+// newTemplate parses the output template from the flags, returning nil when
+// none was given.
+func newTemplate() *template.Template {
+	var (
+		tmpl *template.Template
+		err  error
+	)
+	switch {
+	case templateArg != "" && templateFileArg != "":
+		err = errors.New("--template and --template-file cannot be used together")
+	case templateArg != "":
+		tmpl, err = processors.ParseTemplate(templateArg)
+	case templateFileArg != "":
+		tmpl, err = processors.LoadTemplate(templateFileArg)
+	}
+	if err != nil {
+		reportFatalError(err)
+	}
+	return tmpl
+}
+
+// newProcessors builds the processors shared by every command, in order:
+// redaction (when redactor is not nil), transformation, enrichment, computed fields, filtering,
+// deduplication and sampling. The returned function releases
+// them once the input is over.
+func newProcessors(ctx context.Context, redactor *processors.Redactor) ([]service.EntryProcessor, func()) {
+	processorsList := make([]service.EntryProcessor, 0, 8)
+	releasers := make([]func(), 0, 3)
+	release := func() {
+		for _, r := range releasers {
+			r()
+		}
+	}
+
+	if redactor != nil {
+		processorsList = append(processorsList, redactor)
+		releasers = append(releasers, func() {
+			reportRedactions(ctx, redactor)
+		})
+	}
+	if transformArg != "" {
+		steps, err := processors.LoadTransformSteps(transformArg)
+		if err != nil {
+			reportFatalError(err)
+		}
+		transformer, err := processors.NewTransformer(steps, newMatcher)
+		if err != nil {
+			reportFatalError(err)
+		}
+		processorsList = append(processorsList, transformer)
+		releasers = append(releasers, func() {
+			_ = transformer.Close()
+		})
+	}
+	if enrichArg != "" {
+		rules, err := processors.LoadEnrichRules(enrichArg)
+		if err != nil {
+			reportFatalError(err)
+		}
+		enricher, err := processors.NewEnricher(rules, processors.WithReloadErrorHandler(func(err error) {
+			logctx.Warn(ctx, "error reloading the enrichment tables", zap.Error(err))
+		}))
+		if err != nil {
+			reportFatalError(err)
+		}
+		processorsList = append(processorsList, enricher)
+	}
+	if len(computeArg) > 0 {
+		rules := make([]processors.ComputeRule, len(computeArg))
+		for i, spec := range computeArg {
+			rule, err := processors.ParseComputeRule(spec)
+			if err != nil {
+				reportFatalError(err)
+			}
+			rules[i] = rule
+		}
+		computer, err := processors.NewComputer(rules)
+		if err != nil {
+			reportFatalError(err)
+		}
+		processorsList = append(processorsList, computer)
+	}
+	if filterArg != "" {
+		matcher, err := entryreader.NewMatcher(filterArg)
+		if err != nil {
+			reportFatalError(err)
+		}
+		processorsList = append(processorsList, processors.NewFilter(matcher))
+		releasers = append(releasers, func() {
+			_ = matcher.Close()
+		})
+	}
+	if dedupeArg {
+		processorsList = append(processorsList, processors.NewDeduper(processors.DedupeConfig{
+			Fields: dedupeByArg,
+			Window: dedupeWindowArg,
+		}))
+	}
+	if sampleEveryArg != 0 || sampleRateArg != 0 {
+		sampler, err := processors.NewSampler(processors.SamplerConfig{
+			Every:           sampleEveryArg,
+			Rate:            sampleRateArg,
+			Keys:            sampleByArg,
+			SummaryInterval: sampleSummaryArg,
+		})
+		if err != nil {
+			reportFatalError(err)
+		}
+		processorsList = append(processorsList, sampler)
+	}
+	return processorsList, release
+}
+
+func newMatcher(expr string) (processors.EntryMatcher, error) {
+	return entryreader.NewMatcher(expr)
+}
+
+// newRedactor builds the redaction processor from the flags, returning nil
+// when redaction is disabled.
+func newRedactor() *processors.Redactor {
+	var rules []processors.RedactRule
+	if redactArg {
+		rules = append(rules, processors.DefaultRedactRules()...)
+	}
+	if redactRulesArg != "" {
+		fileRules, err := processors.LoadRedactRules(redactRulesArg)
+		if err != nil {
+			reportFatalError(err)
+		}
+		rules = append(rules, fileRules...)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	var opts []processors.RedactorOption
+	if redactKeyArg != "" {
+		opts = append(opts, processors.WithRedactKey([]byte(redactKeyArg)))
+	}
+	redactor, err := processors.NewRedactor(rules, opts...)
+	if err != nil {
+		reportFatalError(err)
+	}
+	return redactor
+}
+
+func reportRedactions(ctx context.Context, redactor *processors.Redactor) {
+	for rule, count := range redactor.Counts() {
+		logctx.Info(ctx, "redacted values", zap.String("rule", rule), zap.Uint64("count", count))
+	}
+}
+
+// deadLetter collects the entries that could not be parsed or processed; nil
+// when they are only logged (--show-parse-errors).
+var deadLetter *deadletter.Sink
+
+// openDeadLetter sets up deadLetter, writing to the --dead-letter file when
+// given. Without the file, the rejects are only kept in memory, and only
+// when inMemory is set (for the web UI). The raw lines go through redactor,
+// when given. The returned function closes the file and reports how many
+// entries were rejected.
+func openDeadLetter(ctx context.Context, inMemory bool, redactor *processors.Redactor) func() {
+	if deadLetterArg == "" && !inMemory {
+		return func() {}
+	}
+	source := sourceArg
+	if source == "-" {
+		source = "stdin"
+	}
+	var (
+		opts []deadletter.Option
+		f    *os.File
+	)
+	if deadLetterArg != "" {
+		var err error
+		f, err = os.OpenFile(deadLetterArg, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			reportFatalError(fmt.Errorf("could not open the dead-letter file: %w", err))
+		}
+		opts = append(opts, deadletter.WithWriter(f))
+	}
+	if redactor != nil {
+		opts = append(opts, deadletter.WithRedact(redactor.RedactText))
+	}
+	deadLetter = deadletter.New(source, opts...)
+	return func() {
+		if count := deadLetter.Count(); count > 0 {
+			logctx.Info(ctx, "rejected entries", zap.Uint64("count", count), zap.String("dead_letter", deadLetterArg))
+		}
+		if err := deadLetter.Err(); err != nil {
+			logctx.Error(ctx, "error writing the dead-letter file", zap.Error(err))
+		}
+		if f != nil {
+			_ = f.Close()
+		}
+	}
+}
+
 func reportFatalError(err error) {
 	fmt.Println("### ERROR:", err.Error())
 	os.Exit(1)
 }
 
 func logHandler(ctx context.Context, err error) error {
+	if deadLetter != nil {
+		deadLetter.Reject(err)
+	}
 	if showParseErrorsArg {
 		logctx.Error(ctx, "error reading entries", zap.Error(err))
 	}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+	"github.com/jamillosantos/lovr/internal/transport/http/models"
+)
+
+var queryExplainJSONArg = false
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Inspect search queries",
+}
+
+var queryExplainCmd = &cobra.Command{
+	Use:   "explain <query>",
+	Short: "Show how a search or --filter query is parsed",
+	Long: `Show how a search or --filter query is parsed: the clauses it is made of,
+the terms each one searches for once analyzed, and syntax errors with their
+position. Exits with 1 when the query is invalid. Queries starting with - go
+after --, so they are not taken for flags.
+
+Examples:
+
+  $ lovr query explain 'level:(error OR fatal) -route:/health'
+  $ lovr query explain --json 'status:>=500 since:1h'
+  $ lovr query explain -- '-(level:debug OR level:trace)'`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		e, err := entryreader.Explain(strings.Join(args, " "))
+		if err != nil {
+			reportFatalError(err)
+		}
+		if queryExplainJSONArg {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(models.MapQueryExplainResponse(e)); err != nil {
+				reportFatalError(err)
+			}
+		} else {
+			fmt.Print(e.Text())
+		}
+		if e.Error != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	queryExplainCmd.Flags().BoolVar(&queryExplainJSONArg, "json", queryExplainJSONArg, "Print the explanation as JSON, as returned by the /entries/query/explain endpoint")
+	queryCmd.AddCommand(queryExplainCmd)
+	rootCmd.AddCommand(queryCmd)
+}
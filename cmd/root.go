@@ -5,22 +5,51 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"runtime"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/jamillosantos/lovr/internal/domain"
 	"github.com/jamillosantos/lovr/internal/parsers"
 	_ "github.com/jamillosantos/lovr/internal/parsers/json"
 	"github.com/jamillosantos/lovr/internal/service"
-	"github.com/jamillosantos/lovr/internal/service/entryreader"
 	"github.com/jamillosantos/lovr/internal/service/processors"
+	"github.com/jamillosantos/lovr/internal/timestamp"
 )
 
 var (
 	parserArg = "json"
 
 	filterArg          = ""
+	workersArg         = runtime.NumCPU()
+	deadLetterArg      = ""
 	sourceArg          = "-"
 	showParseErrorsArg = false
+	levelAliasesArg    = map[string]string{}
+	timeLayoutsArg     = []string{}
+	timezoneArg        = ""
+	formatArg          = string(processors.FormatTree)
+	timeFormatArg      = processors.DefaultCompactTimeFormat
+	fieldsArg          = []string{}
+	templateArg        = ""
+	templateFileArg    = ""
+	foldFramesArg      = false
+	outputArg          = ""
+	columnsArg         = []string{}
+	redactArg          = false
+	redactRulesArg     = ""
+	redactKeyArg       = ""
+	transformArg       = ""
+	enrichArg          = ""
+	computeArg         = []string{}
+	dedupeArg          = false
+	dedupeByArg        = []string{}
+	dedupeWindowArg    = processors.DefaultDedupeWindow
+	sampleEveryArg     = 0
+	sampleRateArg      = 0.0
+	sampleByArg        = []string{}
+	sampleSummaryArg   = processors.DefaultSampleSummaryInterval
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -43,6 +72,24 @@ Examples:
   Reading from a docker-compose container:
   $ docker-compose logs -f --no-log-prefix api | lovr
 `,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		for alias, level := range levelAliasesArg {
+			if err := domain.RegisterLevelAlias(alias, domain.Level(level)); err != nil {
+				return err
+			}
+		}
+		for _, layout := range timeLayoutsArg {
+			timestamp.RegisterLayout(layout)
+		}
+		if timezoneArg != "" {
+			loc, err := time.LoadLocation(timezoneArg)
+			if err != nil {
+				return fmt.Errorf("invalid timezone: %w", err)
+			}
+			timestamp.SetDefaultLocation(loc)
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
 
@@ -66,21 +113,18 @@ Examples:
 			reportFatalError(err)
 		}
 
-		processorsList := make([]service.EntryProcessor, 0)
-		if filterArg != "" {
-			matcher, err := entryreader.NewMatcher(filterArg)
-			if err != nil {
-				reportFatalError(err)
-			}
-			defer func() {
-				_ = matcher.Close()
-			}()
-			processorsList = append(processorsList, processors.NewFilter(matcher))
-		}
-		processorsList = append(processorsList, processors.NewStdout())
+		redactor := newRedactor()
+		releaseDeadLetter := openDeadLetter(ctx, false, redactor)
+		defer releaseDeadLetter()
+
+		processorsList, releaseProcessors := newProcessors(ctx, redactor)
+		defer releaseProcessors()
+		output, closeOutput := newOutput()
+		processorsList = append(processorsList, output)
 
-		entriesFetcher := service.NewEntriesReader(parser, logHandler)
+		entriesFetcher := service.NewEntriesReader(parser, logHandler, service.WithWorkers(workersArg))
 		runFetcher(ctx, entriesFetcher, processorsList)
+		closeOutput()
 	},
 }
 
@@ -96,6 +140,32 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&showParseErrorsArg, "show-parse-errors", showParseErrorsArg, "Output parse errors to the STDERR")
 	rootCmd.PersistentFlags().StringVarP(&sourceArg, "source", "s", sourceArg, "Filename of the log information (use `-` for STDIN).")
+	rootCmd.PersistentFlags().IntVar(&workersArg, "workers", workersArg, "Entries parsed, filtered and indexed at once. The output keeps the input order. Default: number of CPUs.")
+	rootCmd.PersistentFlags().StringVar(&deadLetterArg, "dead-letter", deadLetterArg, "File where the entries that cannot be parsed or processed are appended, as JSON lines with the reason, source and line number.")
+	rootCmd.PersistentFlags().StringToStringVar(&levelAliasesArg, "level-alias", levelAliasesArg, "Map nonstandard levels to the canonical ones (e.g. 'verbose=trace,35=warning').")
+	rootCmd.PersistentFlags().StringArrayVar(&timeLayoutsArg, "time-layout", timeLayoutsArg, "Additional Go time layout for entry timestamps, tried before the built-in ones (repeatable; commas are part of the layout).")
+	rootCmd.PersistentFlags().StringVar(&timezoneArg, "timezone", timezoneArg, "Time zone for timestamps without zone information (e.g. 'UTC', 'America/Sao_Paulo'). Default: local time zone.")
+	rootCmd.PersistentFlags().StringVar(&formatArg, "format", formatArg, "Terminal output format: tree (pretty-printed entries), compact (one line per entry) or raw (lines as read from the source).")
+	rootCmd.PersistentFlags().StringVar(&timeFormatArg, "time-format", timeFormatArg, "Go time layout of the timestamps in the compact format (e.g. '2006-01-02T15:04:05Z07:00').")
+	rootCmd.PersistentFlags().StringSliceVar(&fieldsArg, "fields", fieldsArg, "Fields printed, in order, after the message in the compact format (e.g. 'service,trace_id,http.status'). Default: every field.")
+	rootCmd.PersistentFlags().StringVar(&templateArg, "template", templateArg, "Go text/template rendering each entry in place of --format (e.g. '{{formatTime \"15:04:05\" .Time}} {{.Message}} {{.Field \"http.status\"}}').")
+	rootCmd.PersistentFlags().StringVar(&templateFileArg, "template-file", templateFileArg, "File with the template rendering each entry (see --template).")
+	rootCmd.PersistentFlags().BoolVar(&foldFramesArg, "fold-frames", foldFramesArg, "Collapse the stack trace frames of the standard library, vendored packages and node_modules into a count.")
+	rootCmd.PersistentFlags().StringVar(&outputArg, "output", outputArg, "Write the normalized entries for other programs instead of the terminal output: json, ndjson, logfmt or csv.")
+	rootCmd.PersistentFlags().StringSliceVar(&columnsArg, "columns", columnsArg, "Fields written as CSV columns, in order, by --output csv (e.g. 'timestamp,level,message,http.status'). Default: timestamp,level,message.")
+	rootCmd.PersistentFlags().BoolVar(&redactArg, "redact", redactArg, "Mask JWTs, AWS access keys, emails and credit card numbers before displaying or indexing entries.")
+	rootCmd.PersistentFlags().StringVar(&redactRulesArg, "redact-rules", redactRulesArg, "JSON file with redaction rules (fields, patterns or detectors with mask, hash or drop actions).")
+	rootCmd.PersistentFlags().StringVar(&redactKeyArg, "redact-key", redactKeyArg, "Key of the hash redaction action, so digests match across runs. Default: a random key per run.")
+	rootCmd.PersistentFlags().StringVar(&transformArg, "transform", transformArg, "JSON file with field transformations (rename, drop, keep, copy, set, cast, flatten) applied before filtering.")
+	rootCmd.PersistentFlags().StringVar(&enrichArg, "enrich", enrichArg, "JSON file with enrichment rules joining fields against CSV/JSON lookup tables (exact or CIDR matching), applied before filtering.")
+	rootCmd.PersistentFlags().StringArrayVar(&computeArg, "compute", computeArg, "Computed field as field=expression (e.g. 'duration_ms=duration_ns / 1e6'), applied before filtering (repeatable).")
+	rootCmd.PersistentFlags().BoolVar(&dedupeArg, "dedupe", dedupeArg, "Collapse consecutive repeats of an entry into one, with a repeat count and the first/last timestamps.")
+	rootCmd.PersistentFlags().StringSliceVar(&dedupeByArg, "dedupe-by", dedupeByArg, "Fields that, along with the message, identify repeated entries. Default: level.")
+	rootCmd.PersistentFlags().DurationVar(&dedupeWindowArg, "dedupe-window", dedupeWindowArg, "Longest gap between two repeats for them to be collapsed.")
+	rootCmd.PersistentFlags().IntVar(&sampleEveryArg, "sample-every", sampleEveryArg, "Keep one out of each N entries with the same level and message (see --sample-by).")
+	rootCmd.PersistentFlags().Float64Var(&sampleRateArg, "sample-rate", sampleRateArg, "Keep at most this many entries per second with the same level and message (see --sample-by).")
+	rootCmd.PersistentFlags().StringSliceVar(&sampleByArg, "sample-by", sampleByArg, "Fields grouping entries for sampling. Default: level,message.")
+	rootCmd.PersistentFlags().DurationVar(&sampleSummaryArg, "sample-summary", sampleSummaryArg, "How often to report how many entries sampling suppressed.")
 	rootCmd.PersistentFlags().StringVarP(&filterArg, "filter", "f", filterArg, "Filter entries using the web UI search syntax (e.g. 'level:error service:api* (timeout OR refused)').")
 
 	// No filters are available yet
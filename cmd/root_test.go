@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/timestamp"
+)
+
+func TestRootCmd_timeLayout(t *testing.T) {
+	restoreLayouts := timestamp.ResetLayouts()
+	t.Cleanup(func() {
+		timeLayoutsArg = []string{}
+		restoreLayouts()
+	})
+
+	flags := rootCmd.PersistentFlags()
+	require.NoError(t, flags.Parse([]string{
+		"--time-layout", "Mon, 02 Jan 2006 15:04:05",
+		"--time-layout", "2006-01-02 15:04:05,000",
+	}))
+	assert.Equal(t, []string{"Mon, 02 Jan 2006 15:04:05", "2006-01-02 15:04:05,000"}, timeLayoutsArg)
+
+	require.NoError(t, rootCmd.PersistentPreRunE(rootCmd, nil))
+	got, ok := timestamp.Parse("Tue, 02 Jan 2024 15:04:05")
+	require.True(t, ok)
+	assert.Equal(t, time.January, got.Month())
+	got, ok = timestamp.Parse("2024-01-02 15:04:05,250")
+	require.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, time.Duration(got.Nanosecond()))
+}
@@ -9,7 +9,9 @@ import (
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 
+	"github.com/jamillosantos/lovr/internal/logctx"
 	"github.com/jamillosantos/lovr/internal/parsers"
 	_ "github.com/jamillosantos/lovr/internal/parsers/json"
 	"github.com/jamillosantos/lovr/internal/service"
@@ -56,26 +58,26 @@ search for log entries on a modern UI.`,
 
 		indexer := processors.NewIndexer(index)
 
-		processorsList := make([]service.EntryProcessor, 0)
-		if filterArg != "" {
-			matcher, err := entryreader.NewMatcher(filterArg)
-			if err != nil {
-				reportFatalError(err)
-			}
-			defer func() {
-				_ = matcher.Close()
-			}()
-			processorsList = append(processorsList, processors.NewFilter(matcher))
-		}
-		processorsList = append(processorsList, processors.NewStdout(), indexer)
+		redactor := newRedactor()
+		releaseDeadLetter := openDeadLetter(ctx, true, redactor)
+		defer releaseDeadLetter()
+
+		processorsList, releaseProcessors := newProcessors(ctx, redactor)
+		defer releaseProcessors()
+		output, closeOutput := newOutput()
+		processorsList = append(processorsList, output, indexer)
 
 		var wc sync.WaitGroup
 
-		entriesFetcher := service.NewEntriesReader(parser, logHandler)
+		entriesFetcher := service.NewEntriesReader(parser, logHandler, service.WithWorkers(workersArg))
 		wc.Add(1)
 		go func() {
 			defer wc.Done()
 			runFetcher(ctx, entriesFetcher, processorsList)
+			closeOutput()
+			if err := indexer.Close(); err != nil {
+				logctx.Error(ctx, "error indexing entries", zap.Error(err))
+			}
 		}()
 
 		entryReader := entryreader.NewReader(index)
@@ -83,6 +85,8 @@ search for log entries on a modern UI.`,
 		opts := []transporthttp.Option{
 			transporthttp.WithBindAddr(bindAddrArg),
 			transporthttp.WithWC(&wc),
+			transporthttp.WithIndexStats(indexer),
+			transporthttp.WithRejects(deadLetter),
 		}
 		if uiFS := ui.FS(); uiFS != nil {
 			opts = append(opts, transporthttp.WithUI(uiFS))
@@ -0,0 +1,146 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jamillosantos/lovr/internal/parsers"
+	"github.com/jamillosantos/lovr/internal/service"
+)
+
+// DefaultKeep is how many rejects Recent returns by default.
+const DefaultKeep = 500
+
+// Reject is an entry lovr could not parse or process.
+type Reject struct {
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+	Source string    `json:"source"`
+	Line   int       `json:"line,omitempty"`
+	Raw    string    `json:"raw"`
+}
+
+// Sink collects the rejected entries: it counts them, keeps the most recent
+// ones in memory and, when given a writer, writes each one as a JSON line.
+// Sink is safe for concurrent use.
+type Sink struct {
+	source string
+	w      io.Writer
+	keep   int
+	redact func(string) string
+
+	mu     sync.Mutex
+	count  uint64
+	recent []Reject
+	next   int
+	err    error
+}
+
+type Option func(*Sink)
+
+// WithWriter writes every reject as a JSON line to w.
+func WithWriter(w io.Writer) Option {
+	return func(s *Sink) {
+		s.w = w
+	}
+}
+
+// WithKeep sets how many rejects are kept in memory. Default: DefaultKeep.
+func WithKeep(keep int) Option {
+	return func(s *Sink) {
+		s.keep = keep
+	}
+}
+
+// WithRedact applies redact to the raw lines before they are kept or
+// written, so secrets in rejected lines do not leak through the sink.
+func WithRedact(redact func(string) string) Option {
+	return func(s *Sink) {
+		s.redact = redact
+	}
+}
+
+// New creates a sink for the rejects of source (a file name, or "stdin").
+func New(source string, opts ...Option) *Sink {
+	s := &Sink{
+		source: source,
+		keep:   DefaultKeep,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Reject records the entry err is about. The raw line and its number are
+// taken from parsers.LineError and service.EntryError; other errors are
+// recorded with their reason only.
+func (s *Sink) Reject(err error) {
+	reject := Reject{
+		Time:   time.Now(),
+		Reason: err.Error(),
+		Source: s.source,
+	}
+	var (
+		lineErr  *parsers.LineError
+		entryErr *service.EntryError
+	)
+	switch {
+	case errors.As(err, &lineErr):
+		reject.Line, reject.Raw = lineErr.Line, lineErr.Raw
+	case errors.As(err, &entryErr):
+		reject.Line, reject.Raw = entryErr.Entry.Line, entryErr.Entry.Raw
+		if reject.Raw == "" {
+			data, _ := json.Marshal(entryErr.Entry.OrderedMap)
+			reject.Raw = string(data)
+		}
+	}
+	if s.redact != nil && reject.Raw != "" {
+		reject.Raw = s.redact(reject.Raw)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	if s.keep > 0 {
+		if len(s.recent) < s.keep {
+			s.recent = append(s.recent, reject)
+		} else {
+			s.recent[s.next] = reject
+		}
+		s.next = (s.next + 1) % s.keep
+	}
+	if s.w != nil && s.err == nil {
+		data, _ := json.Marshal(reject)
+		_, s.err = s.w.Write(append(data, '\n'))
+	}
+}
+
+// Count returns how many entries were rejected.
+func (s *Sink) Count() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Recent returns the most recent rejects, newest first.
+func (s *Sink) Recent() []Reject {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := make([]Reject, 0, len(s.recent))
+	for i := 1; i <= len(s.recent); i++ {
+		r = append(r, s.recent[(s.next-i+len(s.recent))%len(s.recent)])
+	}
+	return r
+}
+
+// Err returns the first error writing to the writer, after which nothing
+// else is written.
+func (s *Sink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
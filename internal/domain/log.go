@@ -1,8 +1,16 @@
 package domain
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
 type Level string
 
 const (
+	LevelTrace   Level = "trace"
 	LevelDebug   Level = "debug"
 	LevelError   Level = "error"
 	LevelInfo    Level = "info"
@@ -13,6 +21,7 @@ const (
 
 var (
 	levelMap = map[Level]string{
+		LevelTrace:   "Trace",
 		LevelDebug:   "Debug",
 		LevelError:   "Error",
 		LevelInfo:    "Info",
@@ -20,8 +29,103 @@ var (
 		LevelFatal:   "Fatal",
 		LevelPanic:   "Panic",
 	}
+
+	// levelAliases maps lowercased level names (and numeric levels in their
+	// decimal form) to the canonical set. Extended by RegisterLevelAlias.
+	levelAliases = map[string]Level{
+		"trc":         LevelTrace,
+		"verbose":     LevelTrace,
+		"dbg":         LevelDebug,
+		"inf":         LevelInfo,
+		"information": LevelInfo,
+		"notice":      LevelInfo,
+		"warn":        LevelWarning,
+		"wrn":         LevelWarning,
+		"err":         LevelError,
+		"crit":        LevelFatal,
+		"critical":    LevelFatal,
+		"alert":       LevelFatal,
+		"emerg":       LevelPanic,
+		"emergency":   LevelPanic,
+		"dpanic":      LevelPanic,
+	}
+
+	// numericLevels covers Bunyan/pino levels (10-60) and syslog severities
+	// (0-7).
+	numericLevels = map[int64]Level{
+		10: LevelTrace,
+		20: LevelDebug,
+		30: LevelInfo,
+		40: LevelWarning,
+		50: LevelError,
+		60: LevelFatal,
+
+		0: LevelPanic,   // emergency
+		1: LevelFatal,   // alert
+		2: LevelFatal,   // critical
+		3: LevelError,   // error
+		4: LevelWarning, // warning
+		5: LevelInfo,    // notice
+		6: LevelInfo,    // informational
+		7: LevelDebug,   // debug
+	}
 )
 
 func (l Level) String() string {
-	return levelMap[l]
+	if s, ok := levelMap[l]; ok {
+		return s
+	}
+	return string(l)
+}
+
+// IsCanonical reports whether l is one of the levels lovr knows about.
+func (l Level) IsCanonical() bool {
+	_, ok := levelMap[l]
+	return ok
+}
+
+// RegisterLevelAlias makes NormalizeLevel resolve alias (case-insensitive;
+// numeric levels in their decimal form, e.g. "35") to level. User aliases take
+// precedence over the built-in ones.
+func RegisterLevelAlias(alias string, level Level) error {
+	level = Level(strings.ToLower(string(level)))
+	if !level.IsCanonical() {
+		return fmt.Errorf("unknown level %q for alias %q", level, alias)
+	}
+	levelAliases[strings.ToLower(strings.TrimSpace(alias))] = level
+	return nil
+}
+
+// NormalizeLevel resolves a raw level value, as found in the log entry, to the
+// canonical level set: names in any case, aliases (WARN, ERR, critical...),
+// and numeric levels (Bunyan/pino and syslog severities), either as numbers
+// or numeric strings. Unknown values are returned lowercased with ok=false.
+func NormalizeLevel(v interface{}) (level Level, ok bool) {
+	switch vv := v.(type) {
+	case string:
+		s := strings.ToLower(strings.TrimSpace(vv))
+		if l, found := levelAliases[s]; found {
+			return l, true
+		}
+		if l := Level(s); l.IsCanonical() {
+			return l, true
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if l, found := numericLevels[n]; found {
+				return l, true
+			}
+		}
+		return Level(s), false
+	case float64:
+		if vv != math.Trunc(vv) {
+			return Level(strconv.FormatFloat(vv, 'f', -1, 64)), false
+		}
+		return NormalizeLevel(strconv.FormatInt(int64(vv), 10))
+	case int:
+		return NormalizeLevel(strconv.Itoa(vv))
+	case int64:
+		return NormalizeLevel(strconv.FormatInt(vv, 10))
+	default:
+		return "", false
+	}
 }
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  interface{}
+		want   Level
+		wantOK bool
+	}{
+		{"canonical", "info", LevelInfo, true},
+		{"uppercase", "WARN", LevelWarning, true},
+		{"mixed case canonical", "Error", LevelError, true},
+		{"short alias", "ERR", LevelError, true},
+		{"trace", "trace", LevelTrace, true},
+		{"critical", "critical", LevelFatal, true},
+		{"bunyan number", float64(40), LevelWarning, true},
+		{"pino number", float64(10), LevelTrace, true},
+		{"numeric string", "50", LevelError, true},
+		{"syslog severity", float64(3), LevelError, true},
+		{"unknown value", "Chatty", Level("chatty"), false},
+		{"unknown number", float64(35), Level("35"), false},
+		{"fractional number", 2.5, Level("2.5"), false},
+		{"unsupported type", true, Level(""), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeLevel(tt.input)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestRegisterLevelAlias(t *testing.T) {
+	t.Cleanup(func() {
+		delete(levelAliases, "chatty")
+		delete(levelAliases, "35")
+	})
+
+	assert.NoError(t, RegisterLevelAlias("Chatty", "TRACE"))
+	assert.NoError(t, RegisterLevelAlias("35", LevelWarning))
+	assert.Error(t, RegisterLevelAlias("loud", "shouting"))
+
+	got, ok := NormalizeLevel("CHATTY")
+	assert.True(t, ok)
+	assert.Equal(t, LevelTrace, got)
+
+	got, ok = NormalizeLevel(float64(35))
+	assert.True(t, ok)
+	assert.Equal(t, LevelWarning, got)
+}
+
+func TestLevel_String(t *testing.T) {
+	assert.Equal(t, "Warning", LevelWarning.String())
+	assert.Equal(t, "Trace", LevelTrace.String())
+	assert.Equal(t, "verbose2", Level("verbose2").String())
+}
@@ -11,7 +11,32 @@ type LogField struct {
 	Value interface{}
 }
 
-type Entry = orderedmap.OrderedMap
+// Entry is a decoded log line: its keys in input order, plus the line exactly
+// as read from the source.
+type Entry struct {
+	orderedmap.OrderedMap
+	Raw string
+	// Line is the line number in the source, 0 when unknown.
+	Line int
+	// ID, when set, is the ID the entry is indexed under; an entry indexed
+	// with the ID of a previous one replaces it. Generated when empty.
+	ID string
+	// Repeat is set on the entries standing for a run of repeats collapsed
+	// by the deduplication, nil otherwise.
+	Repeat *Repeat
+}
+
+// Repeat tells how many times an entry was seen in a row, and when.
+type Repeat struct {
+	Count uint64
+	First time.Time
+	Last  time.Time
+}
+
+// NewEntry returns an empty entry, ready to be filled with Set.
+func NewEntry() *Entry {
+	return &Entry{OrderedMap: *orderedmap.New()}
+}
 
 type LogEntry struct {
 	ID         string
@@ -21,4 +46,9 @@ type LogEntry struct {
 	Fields     orderedmap.OrderedMap
 	Caller     string
 	Stacktrace string
+	Raw        string
+
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
 }
@@ -0,0 +1,325 @@
+// Package expr evaluates small expressions over the fields of an entry:
+//
+//	duration_ns / 1e6
+//	latency_ms > 500 ? "slow" : "fast"
+//	extract(path, "^/api/v\\d+/([^/]+)")
+//	lower(coalesce(user.email, user.name, "anonymous"))
+//
+// Values are numbers (float64), strings, booleans and null. Names are field
+// paths (http.status); names that are not identifiers go between backticks
+// (`content-type`). Missing fields are null, and so is the result of
+// operations on values of the wrong type ("a" * 2), so a missing or odd field
+// never fails a whole entry.
+//
+// Operators, from the lowest precedence: ?: (conditional), || (or), && (and),
+// == !=, < <= > >=, + -, * / %, and the unary ! (not) and -. + concatenates
+// when either side is a string. Comparisons of a number with a numeric string
+// are numeric.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Lookup returns the value of a field.
+type Lookup func(path string) (interface{}, bool)
+
+// Expr is a compiled expression. It is safe for concurrent use.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses an expression.
+func Compile(src string) (*Expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseTernary()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("invalid expression: %w", p.unexpected())
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Eval evaluates the expression, looking the fields up with lookup.
+func (e *Expr) Eval(lookup Lookup) (interface{}, error) {
+	return e.root.eval(lookup)
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+type node interface {
+	eval(lookup Lookup) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(Lookup) (interface{}, error) {
+	return n.value, nil
+}
+
+type fieldNode struct {
+	path string
+}
+
+func (n *fieldNode) eval(lookup Lookup) (interface{}, error) {
+	v, _ := lookup(n.path)
+	switch vv := v.(type) {
+	case nil, string, float64, bool:
+		return vv, nil
+	case int:
+		return float64(vv), nil
+	case int64:
+		return float64(vv), nil
+	default:
+		// Objects and arrays are only useful as text.
+		return fmt.Sprint(vv), nil
+	}
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(lookup Lookup) (interface{}, error) {
+	v, err := n.operand.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !truthy(v), nil
+	}
+	f, ok := toNumber(v)
+	if !ok {
+		return nil, nil
+	}
+	return -f, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(lookup Lookup) (interface{}, error) {
+	left, err := n.left.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit.
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(lookup)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(lookup)
+		return truthy(right), err
+	}
+
+	right, err := n.right.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		c, ok := compare(left, right)
+		if !ok {
+			return nil, nil
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "+":
+		_, ls := left.(string)
+		_, rs := right.(string)
+		if ls || rs {
+			if left == nil || right == nil {
+				return nil, nil
+			}
+			return toString(left) + toString(right), nil
+		}
+	}
+
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, nil
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, nil
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, nil
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+type condNode struct {
+	cond, then, otherwise node
+}
+
+func (n *condNode) eval(lookup Lookup) (interface{}, error) {
+	c, err := n.cond.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(c) {
+		return n.then.eval(lookup)
+	}
+	return n.otherwise.eval(lookup)
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(lookup Lookup) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(lookup)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := n.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+func truthy(v interface{}) bool {
+	switch vv := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return vv
+	case float64:
+		return vv != 0
+	case string:
+		return vv != ""
+	default:
+		return true
+	}
+}
+
+// toNumber converts numbers, numeric strings and booleans.
+func toNumber(v interface{}) (float64, bool) {
+	switch vv := v.(type) {
+	case float64:
+		return vv, true
+	case string:
+		f, err := strconv.ParseFloat(vv, 64)
+		return f, err == nil
+	case bool:
+		if vv {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+func toString(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64)
+	default:
+		return fmt.Sprint(vv)
+	}
+}
+
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+	return a == b
+}
+
+// compare orders two numbers (or a number and a numeric string) or two
+// strings.
+func compare(a, b interface{}) (int, bool) {
+	as, aStr := a.(string)
+	bs, bStr := b.(string)
+	if aStr && bStr {
+		switch {
+		case as < bs:
+			return -1, true
+		case as > bs:
+			return 1, true
+		}
+		return 0, true
+	}
+	_, aBool := a.(bool)
+	_, bBool := b.(bool)
+	if aBool || bBool {
+		return 0, false
+	}
+	x, ok := toNumber(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := toNumber(b)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	}
+	return 0, true
+}
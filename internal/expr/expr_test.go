@@ -0,0 +1,114 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	fields := map[string]interface{}{
+		"duration_ns": float64(1_500_000),
+		"latency_ms":  float64(742),
+		"status":      "503",
+		"path":        "/api/v2/orders/123",
+		"user.name":   "Alice",
+		"user.email":  "",
+		"ok":          false,
+		"tags":        []interface{}{"a", "b"},
+	}
+	lookup := func(path string) (interface{}, bool) {
+		v, ok := fields[path]
+		return v, ok
+	}
+
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		{"duration_ns / 1e6", 1.5},
+		{"1 + 2 * 3 - 4 / 2", 5.0},
+		{"(1 + 2) * 3 % 4", 1.0},
+		{"-latency_ms", -742.0},
+		{"latency_ms > 500", true},
+		{"latency_ms > 500 ? 'slow' : 'fast'", "slow"},
+		{"if(latency_ms <= 500, 'fast', 'slow')", "slow"},
+		{"status == 503", true},
+		{"status >= 500 && status < 600", true},
+		{"status == '503' and not ok", true},
+		{"ok || missing", false},
+		{"!ok", true},
+		{"missing", nil},
+		{"missing * 2", nil},
+		{"'a' * 2", nil},
+		{"latency_ms / 0", nil},
+		{"missing == null", true},
+		{"`user.name` + ' <' + coalesce(user.email, 'none') + '>'", "Alice <none>"},
+		{"'n=' + latency_ms", "n=742"},
+		{"lower(user.name)", "alice"},
+		{"upper(trim('  x '))", "X"},
+		{"len(path)", 18.0},
+		{"contains(path, 'orders')", true},
+		{"startsWith(path, '/api')", true},
+		{"endsWith(path, '/123')", true},
+		{"replace(path, '/', '.')", ".api.v2.orders.123"},
+		{"substr(path, 1, 3)", "api"},
+		{"substr(path, 15)", "123"},
+		{`extract(path, "^/api/v\\d+/([^/]+)")`, "orders"},
+		{`extract(path, "v(\\d+)/(\\w+)", 2)`, "orders"},
+		{`extract(path, "\\d+$")`, "123"},
+		{`extract(path, "^/web/(.*)")`, nil},
+		{`matches(path, "orders/\\d+$")`, true},
+		{"round(duration_ns / 1e6 * 1.23456, 2)", 1.85},
+		{"round(2.5)", 3.0},
+		{"floor(-1.5) + ceil(1.2) + abs(-3)", 3.0},
+		{"min(3, latency_ms, 10) + max(1, '7')", 10.0},
+		{"number('42') + 1", 43.0},
+		{"string(42) + 1", "421"},
+		{"tags", "[a b]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Compile(tt.src)
+			require.NoError(t, err)
+			got, err := e.Eval(lookup)
+			require.NoError(t, err)
+			if f, ok := tt.want.(float64); ok {
+				assert.InDelta(t, f, got, 1e-9)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompile_errors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 2",
+		"'unterminated",
+		"unknown(1)",
+		"lower()",
+		"lower(1, 2)",
+		"a ? b",
+		`extract(path, "(")`,
+		"1 # 2",
+	} {
+		t.Run(src, func(t *testing.T) {
+			_, err := Compile(src)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExpr_Eval_errors(t *testing.T) {
+	e, err := Compile("extract(path, pattern)")
+	require.NoError(t, err)
+	_, err = e.Eval(func(path string) (interface{}, bool) {
+		return map[string]interface{}{"path": "/x", "pattern": "("}[path], true
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type function struct {
+	minArgs, maxArgs int // maxArgs < 0: variadic
+	usage            string
+	call             func(args []interface{}) (interface{}, error)
+}
+
+// functions lists the built-in functions. Null arguments give null, except
+// for if, coalesce and string.
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"if": {3, 3, "if(cond, then, else)", func(args []interface{}) (interface{}, error) {
+			if truthy(args[0]) {
+				return args[1], nil
+			}
+			return args[2], nil
+		}},
+		"coalesce": {1, -1, "coalesce(a, b, ...)", func(args []interface{}) (interface{}, error) {
+			for _, a := range args {
+				if a != nil && a != "" {
+					return a, nil
+				}
+			}
+			return nil, nil
+		}},
+		"string": {1, 1, "string(x)", func(args []interface{}) (interface{}, error) {
+			return toString(args[0]), nil
+		}},
+		"number": {1, 1, "number(x)", nullable(func(args []interface{}) (interface{}, error) {
+			if f, ok := toNumber(args[0]); ok {
+				return f, nil
+			}
+			return nil, nil
+		})},
+		"lower": stringFunc("lower(s)", strings.ToLower),
+		"upper": stringFunc("upper(s)", strings.ToUpper),
+		"trim":  stringFunc("trim(s)", strings.TrimSpace),
+		"len": {1, 1, "len(s)", nullable(func(args []interface{}) (interface{}, error) {
+			return float64(utf8.RuneCountInString(toString(args[0]))), nil
+		})},
+		"contains":   stringPredicate("contains(s, sub)", strings.Contains),
+		"startsWith": stringPredicate("startsWith(s, prefix)", strings.HasPrefix),
+		"endsWith":   stringPredicate("endsWith(s, suffix)", strings.HasSuffix),
+		"replace": {3, 3, "replace(s, old, new)", nullable(func(args []interface{}) (interface{}, error) {
+			return strings.ReplaceAll(toString(args[0]), toString(args[1]), toString(args[2])), nil
+		})},
+		"substr": {2, 3, "substr(s, start[, length])", nullable(func(args []interface{}) (interface{}, error) {
+			runes := []rune(toString(args[0]))
+			start, ok := toNumber(args[1])
+			if !ok {
+				return nil, nil
+			}
+			from := clamp(int(start), len(runes))
+			to := len(runes)
+			if len(args) == 3 {
+				length, ok := toNumber(args[2])
+				if !ok {
+					return nil, nil
+				}
+				to = clamp(from+int(length), len(runes))
+			}
+			return string(runes[from:to]), nil
+		})},
+		"matches": {2, 2, "matches(s, regex)", nullable(func(args []interface{}) (interface{}, error) {
+			re, err := compileRegexp(toString(args[1]))
+			if err != nil {
+				return nil, err
+			}
+			return re.MatchString(toString(args[0])), nil
+		})},
+		"extract": {2, 3, "extract(s, regex[, group])", nullable(func(args []interface{}) (interface{}, error) {
+			re, err := compileRegexp(toString(args[1]))
+			if err != nil {
+				return nil, err
+			}
+			group := 1
+			if re.NumSubexp() == 0 {
+				group = 0
+			}
+			if len(args) == 3 {
+				g, ok := toNumber(args[2])
+				if !ok {
+					return nil, nil
+				}
+				group = int(g)
+			}
+			if group < 0 || group > re.NumSubexp() {
+				return nil, fmt.Errorf("the regex has no group %d", group)
+			}
+			m := re.FindStringSubmatch(toString(args[0]))
+			if m == nil {
+				return nil, nil
+			}
+			return m[group], nil
+		})},
+		"round": {1, 2, "round(x[, digits])", nullable(func(args []interface{}) (interface{}, error) {
+			x, ok := toNumber(args[0])
+			if !ok {
+				return nil, nil
+			}
+			digits := 0.0
+			if len(args) == 2 {
+				if digits, ok = toNumber(args[1]); !ok {
+					return nil, nil
+				}
+			}
+			p := math.Pow(10, digits)
+			return math.Round(x*p) / p, nil
+		})},
+		"floor": mathFunc("floor(x)", math.Floor),
+		"ceil":  mathFunc("ceil(x)", math.Ceil),
+		"abs":   mathFunc("abs(x)", math.Abs),
+		"min": {1, -1, "min(a, b, ...)", nullable(func(args []interface{}) (interface{}, error) {
+			return fold(args, math.Min)
+		})},
+		"max": {1, -1, "max(a, b, ...)", nullable(func(args []interface{}) (interface{}, error) {
+			return fold(args, math.Max)
+		})},
+	}
+}
+
+// nullable makes a function return null when any argument is null.
+func nullable(fn func(args []interface{}) (interface{}, error)) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		for _, a := range args {
+			if a == nil {
+				return nil, nil
+			}
+		}
+		return fn(args)
+	}
+}
+
+func stringFunc(usage string, fn func(string) string) function {
+	return function{1, 1, usage, nullable(func(args []interface{}) (interface{}, error) {
+		return fn(toString(args[0])), nil
+	})}
+}
+
+func stringPredicate(usage string, fn func(s, sub string) bool) function {
+	return function{2, 2, usage, nullable(func(args []interface{}) (interface{}, error) {
+		return fn(toString(args[0]), toString(args[1])), nil
+	})}
+}
+
+func mathFunc(usage string, fn func(float64) float64) function {
+	return function{1, 1, usage, nullable(func(args []interface{}) (interface{}, error) {
+		x, ok := toNumber(args[0])
+		if !ok {
+			return nil, nil
+		}
+		return fn(x), nil
+	})}
+}
+
+func fold(args []interface{}, fn func(a, b float64) float64) (interface{}, error) {
+	var r float64
+	for i, a := range args {
+		x, ok := toNumber(a)
+		if !ok {
+			return nil, nil
+		}
+		if i == 0 {
+			r = x
+			continue
+		}
+		r = fn(r, x)
+	}
+	return r, nil
+}
+
+func clamp(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+// regexps caches the compiled regular expressions, usually literals.
+var regexps sync.Map
+
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	regexps.Store(pattern, re)
+	return re, nil
+}
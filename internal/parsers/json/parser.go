@@ -28,6 +28,8 @@ func init() {
 	parsers.Register("json", NewJSONParser)
 }
 
+var _ parsers.LineParser = (*JSONParser)(nil)
+
 func NewJSONParser(r io.Reader) (parsers.Parser, error) {
 	s := bufio.NewScanner(r)
 	s.Buffer(make([]byte, maxBufferSize), maxBufferSize) // 32k
@@ -42,11 +44,27 @@ func (p *JSONParser) Next() (domain.Entry, error) {
 	if !p.s.Scan() {
 		return domain.Entry{}, io.EOF
 	}
-	jsonBytes := p.s.Bytes()
+	return p.ParseLine(p.s.Bytes(), p.currentLine)
+}
+
+// NextLine returns a copy of the next line and its number.
+func (p *JSONParser) NextLine() ([]byte, int, error) {
+	p.currentLine++
+	if !p.s.Scan() {
+		return nil, 0, io.EOF
+	}
+	return append([]byte(nil), p.s.Bytes()...), p.currentLine, nil
+}
 
+// ParseLine decodes a line read by NextLine. It is safe for concurrent use.
+func (p *JSONParser) ParseLine(jsonBytes []byte, lineNumber int) (domain.Entry, error) {
 	var data orderedmap.OrderedMap
 	if err := json.Unmarshal(jsonBytes, &data); err != nil {
-		return domain.Entry{}, fmt.Errorf("%w: invalid JSON at line %d: %s", ErrInvalidEntryFormat, p.currentLine, err.Error())
+		return domain.Entry{}, &parsers.LineError{
+			Line: lineNumber,
+			Raw:  string(jsonBytes),
+			Err:  fmt.Errorf("%w: invalid JSON at line %d: %s", ErrInvalidEntryFormat, lineNumber, err.Error()),
+		}
 	}
-	return data, nil
+	return domain.Entry{OrderedMap: data, Raw: string(jsonBytes), Line: lineNumber}, nil
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/parsers"
 )
 
 func TestJSONParser_Next(t *testing.T) {
@@ -25,6 +27,7 @@ this is not a JSON`)
 	field2, ok := entry.Get("field2")
 	require.True(t, ok)
 	assert.Equal(t, float64(2), field2)
+	assert.Equal(t, `{"level":"error","msg":"error message","field1":"value1","field2":2}`, entry.Raw)
 
 	entry, err = p.Next()
 	require.NoError(t, err)
@@ -38,3 +41,32 @@ this is not a JSON`)
 	_, err = p.Next()
 	require.ErrorIs(t, err, io.EOF)
 }
+
+func TestJSONParser_NextLine(t *testing.T) {
+	r := strings.NewReader("{\"msg\":\"first\"}\nnot JSON\n")
+	p, err := NewJSONParser(r)
+	require.NoError(t, err)
+	lp := p.(*JSONParser)
+
+	line1, n1, err := lp.NextLine()
+	require.NoError(t, err)
+	line2, n2, err := lp.NextLine()
+	require.NoError(t, err)
+	_, _, err = lp.NextLine()
+	require.ErrorIs(t, err, io.EOF)
+
+	// The lines are copies: parsing them after reading ahead is fine.
+	entry, err := lp.ParseLine(line1, n1)
+	require.NoError(t, err)
+	assert.Equal(t, `{"msg":"first"}`, entry.Raw)
+
+	assert.Equal(t, 1, entry.Line)
+
+	_, err = lp.ParseLine(line2, n2)
+	require.ErrorIs(t, err, ErrInvalidEntryFormat)
+	assert.Contains(t, err.Error(), "line 2")
+	var lineErr *parsers.LineError
+	require.ErrorAs(t, err, &lineErr)
+	assert.Equal(t, 2, lineErr.Line)
+	assert.Equal(t, "not JSON", lineErr.Raw)
+}
@@ -7,3 +7,28 @@ import (
 type Parser interface {
 	Next() (domain.Entry, error)
 }
+
+// LineError is returned for lines that cannot be parsed, carrying the line
+// so it can be reported.
+type LineError struct {
+	Line int
+	Raw  string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineParser is implemented by parsers of one entry per line, allowing
+// reading the lines (sequential) to be split from decoding them (safe for
+// concurrent use). Next is NextLine followed by ParseLine.
+type LineParser interface {
+	Parser
+	NextLine() ([]byte, int, error)
+	ParseLine(line []byte, lineNumber int) (domain.Entry, error)
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"io"
+	"time"
 
 	"github.com/jamillosantos/lovr/internal/domain"
 )
 
+// DefaultEmitInterval is how often the EntryEmitters are polled while no
+// entry comes in.
+const DefaultEmitInterval = time.Second
+
 var (
 	// ErrSkipEntry should be returned by an EntryProcessor to indicate that the entry should be skipped.
 	ErrSkipEntry = errors.New("skip entry")
@@ -17,28 +22,114 @@ type EntryFetcher interface {
 	Next() (domain.Entry, error)
 }
 
+// LineFetcher is implemented by fetchers that can split reading a line from
+// decoding it (parsers.LineParser), so decoding can run on the worker pool.
+// NextLine is only called sequentially; ParseLine must be safe for
+// concurrent use.
+type LineFetcher interface {
+	EntryFetcher
+	NextLine() ([]byte, int, error)
+	ParseLine(line []byte, lineNumber int) (domain.Entry, error)
+}
+
 type EntriesReader struct {
 	fetcher      EntryFetcher
 	errorHandler func(ctx context.Context, err error) error
+	workers      int
+	emitInterval time.Duration
 }
 
 type EntryProcessor interface {
 	Process(ctx context.Context, entry *domain.Entry) error
 }
 
-func NewEntriesReader(fetcher EntryFetcher, errorHandler func(ctx context.Context, err error) error) *EntriesReader {
-	return &EntriesReader{
+// EntryError is handed to the error handler when a processor fails on an
+// entry.
+type EntryError struct {
+	Entry *domain.Entry
+	Err   error
+}
+
+func (e *EntryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// ConcurrentProcessor marks processors that are safe for concurrent use and
+// do not depend on the order of the entries. With more than one worker, they
+// run on the worker pool, unless an EntryEmitter comes before them; the other
+// processors see the entries one at a time, in input order.
+type ConcurrentProcessor interface {
+	EntryProcessor
+	ConcurrentSafe()
+}
+
+// EntryEmitter is implemented by processors that produce entries of their
+// own, such as summaries of the entries they suppressed. Emit is called after
+// every entry read, every emit interval while no entry comes in, and once
+// more, with final set, when the input is over. The entries returned go
+// through the processors that follow the emitter.
+type EntryEmitter interface {
+	Emit(ctx context.Context, final bool) []*domain.Entry
+}
+
+type EntriesReaderOption func(*EntriesReader)
+
+// WithWorkers sets how many entries are parsed and run through the
+// ConcurrentProcessors at once. Default: 1, everything runs in the calling
+// goroutine.
+func WithWorkers(workers int) EntriesReaderOption {
+	return func(r *EntriesReader) {
+		r.workers = workers
+	}
+}
+
+// WithEmitInterval sets how often the EntryEmitters are polled while no entry
+// comes in, so they do not wait for the next one to report. Zero disables it.
+// Default: DefaultEmitInterval.
+func WithEmitInterval(interval time.Duration) EntriesReaderOption {
+	return func(r *EntriesReader) {
+		r.emitInterval = interval
+	}
+}
+
+func NewEntriesReader(fetcher EntryFetcher, errorHandler func(ctx context.Context, err error) error, opts ...EntriesReaderOption) *EntriesReader {
+	r := &EntriesReader{
 		fetcher:      fetcher,
 		errorHandler: errorHandler,
+		workers:      1,
+		emitInterval: DefaultEmitInterval,
+	}
+	for _, o := range opts {
+		o(r)
 	}
+	return r
 }
 
+// Start reads the entries until the input is over (returning io.EOF), the
+// context is cancelled or the error handler returns an error.
 func (r *EntriesReader) Start(ctx context.Context, entryProcessors ...EntryProcessor) error {
+	if r.workers > 1 {
+		return r.startPipeline(ctx, entryProcessors)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	items := r.newItemReader(ctx, entryProcessors, false)
+	defer items.stop()
 	for {
-		entry, err := r.fetcher.Next()
+		item, err := items.next(ctx)
 		switch {
 		case errors.Is(err, io.EOF):
+			if _, err := r.run(ctx, nil, true, entryProcessors); err != nil {
+				return err
+			}
 			return err
+		case ctx.Err() != nil:
+			return ctx.Err()
 		case err != nil:
 			err = r.errorHandler(ctx, err)
 			if err != nil {
@@ -53,18 +144,71 @@ func (r *EntriesReader) Start(ctx context.Context, entryProcessors ...EntryProce
 		default:
 		}
 
-	ProcessorLoop:
-		for _, ep := range entryProcessors {
-			err := ep.Process(ctx, &entry)
-			switch {
-			case errors.Is(err, ErrSkipEntry):
-				break ProcessorLoop
-			case err != nil:
-				err = r.errorHandler(ctx, err)
-				if err != nil {
-					return err
-				}
+		if _, err := r.run(ctx, item.entries, false, entryProcessors); err != nil {
+			return err
+		}
+	}
+}
+
+// run runs the entries through the processors, polling the EntryEmitters
+// after each one (and once more when final or without entries). It returns
+// the entries that went through every processor, emitted ones included.
+func (r *EntriesReader) run(ctx context.Context, entries []*domain.Entry, final bool, entryProcessors []EntryProcessor) ([]*domain.Entry, error) {
+	passed := make([]*domain.Entry, 0, len(entries))
+	for _, entry := range entries {
+		ok, err := r.process(ctx, entry, entryProcessors)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			passed = append(passed, entry)
+		}
+		if passed, err = r.emit(ctx, false, entryProcessors, passed); err != nil {
+			return nil, err
+		}
+	}
+	if final || len(entries) == 0 {
+		return r.emit(ctx, final, entryProcessors, passed)
+	}
+	return passed, nil
+}
+
+// process runs the entry through the processors until one of them skips it,
+// reporting whether none did.
+func (r *EntriesReader) process(ctx context.Context, entry *domain.Entry, entryProcessors []EntryProcessor) (bool, error) {
+	for _, ep := range entryProcessors {
+		err := ep.Process(ctx, entry)
+		switch {
+		case errors.Is(err, ErrSkipEntry):
+			return false, nil
+		case err != nil:
+			err = r.errorHandler(ctx, &EntryError{Entry: entry, Err: err})
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// emit collects the entries produced by EntryEmitters and runs them through
+// the processors that follow each emitter, appending those that went
+// through all of them to passed.
+func (r *EntriesReader) emit(ctx context.Context, final bool, entryProcessors []EntryProcessor, passed []*domain.Entry) ([]*domain.Entry, error) {
+	for i, ep := range entryProcessors {
+		emitter, ok := ep.(EntryEmitter)
+		if !ok {
+			continue
+		}
+		for _, entry := range emitter.Emit(ctx, final) {
+			ok, err := r.process(ctx, entry, entryProcessors[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				passed = append(passed, entry)
 			}
 		}
 	}
+	return passed, nil
 }
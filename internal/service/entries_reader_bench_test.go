@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+
+	"github.com/jamillosantos/lovr/internal/parsers"
+	_ "github.com/jamillosantos/lovr/internal/parsers/json"
+	"github.com/jamillosantos/lovr/internal/service"
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+	"github.com/jamillosantos/lovr/internal/service/processors"
+)
+
+// BenchmarkEntriesReader measures the throughput (MB/s) of the reader
+// filtering a generated log streamed to the parser and printing it, with one
+// worker, four and one per CPU. The log has 16 MiB by default; LOVR_BENCH_MB
+// sets another size:
+//
+//	LOVR_BENCH_MB=64 go test ./internal/service -run '^$' -bench EntriesReader -benchtime 3x
+func BenchmarkEntriesReader(b *testing.B) {
+	size := benchSize(b, 16)
+	benchWorkers(b, size, func(b *testing.B) ([]service.EntryProcessor, func()) {
+		matcher, err := entryreader.NewMatcher("level:error OR http.status:300")
+		if err != nil {
+			b.Fatal(err)
+		}
+		return []service.EntryProcessor{
+			processors.NewFilter(matcher),
+			processors.NewStdout(processors.WithWriter(io.Discard)),
+		}, func() {
+			_ = matcher.Close()
+		}
+	})
+}
+
+// BenchmarkEntriesReader_indexer measures the same as BenchmarkEntriesReader,
+// with the entries indexed as `lovr web` does. The in-memory index is what
+// takes most of the time, so the log has 1 MiB by default.
+func BenchmarkEntriesReader_indexer(b *testing.B) {
+	size := benchSize(b, 1)
+	benchWorkers(b, size, func(b *testing.B) ([]service.EntryProcessor, func()) {
+		index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+		if err != nil {
+			b.Fatal(err)
+		}
+		indexer := processors.NewIndexer(index)
+		return []service.EntryProcessor{indexer}, func() {
+			if err := indexer.Close(); err != nil {
+				b.Fatal(err)
+			}
+			_ = index.Close()
+		}
+	})
+}
+
+// benchSize returns the size of the generated log, LOVR_BENCH_MB or mb MiB.
+func benchSize(b *testing.B, mb int) int64 {
+	if v := os.Getenv("LOVR_BENCH_MB"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			b.Fatalf("invalid LOVR_BENCH_MB %q", v)
+		}
+		mb = n
+	}
+	return int64(mb) << 20
+}
+
+// benchWorkers runs the reader over a generated log of the given size, with
+// one worker, four and one per CPU, through the processors setup returns.
+// The returned func runs at the end of each pass, e.g. to flush the index.
+func benchWorkers(b *testing.B, size int64, setup func(b *testing.B) ([]service.EntryProcessor, func())) {
+	// The last line goes past the size: count what the parser will read.
+	total, err := io.Copy(io.Discard, newSyntheticLog(size))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i, workers := range []int{1, 4, runtime.GOMAXPROCS(0)} {
+		if i == 2 && (workers == 1 || workers == 4) {
+			continue
+		}
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(total)
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				procs, done := setup(b)
+				parser, err := parsers.New("json", newSyntheticLog(size))
+				if err != nil {
+					b.Fatal(err)
+				}
+				r := service.NewEntriesReader(parser, func(_ context.Context, err error) error {
+					return err
+				}, service.WithWorkers(workers))
+				b.StartTimer()
+
+				if err := r.Start(context.Background(), procs...); err != io.EOF {
+					b.Fatal(err)
+				}
+				done()
+			}
+		})
+	}
+}
+
+// syntheticLog streams JSON lines until size bytes were read, ending with a
+// whole line.
+type syntheticLog struct {
+	size int64
+	read int64
+	line int
+	buf  []byte
+	rest []byte
+}
+
+func newSyntheticLog(size int64) *syntheticLog {
+	return &syntheticLog{size: size}
+}
+
+var syntheticLevels = []string{"debug", "info", "warn", "error"}
+
+func (l *syntheticLog) Read(p []byte) (int, error) {
+	if len(l.rest) == 0 {
+		if l.read >= l.size {
+			return 0, io.EOF
+		}
+		i := l.line
+		l.line++
+		l.buf = fmt.Appendf(l.buf[:0], `{"ts":"2026-01-02T10:%02d:%02d.%03dZ","level":%q,"msg":"request %d served","service":"api-%d","http":{"status":%d,"path":"/v1/items/%d"},"duration_ms":%d}`+"\n",
+			i/60000%60, i/1000%60, i%1000, syntheticLevels[i%len(syntheticLevels)], i, i%5, 200+i%3*100, i, i%997)
+		l.rest = l.buf
+	}
+	n := copy(p, l.rest)
+	l.rest = l.rest[n:]
+	l.read += int64(n)
+	return n, nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+type sliceFetcher []string
+
+func (f *sliceFetcher) Next() (domain.Entry, error) {
+	if len(*f) == 0 {
+		return domain.Entry{}, io.EOF
+	}
+	entry := domain.NewEntry()
+	entry.Set("msg", (*f)[0])
+	*f = (*f)[1:]
+	return *entry, nil
+}
+
+type recordProcessor struct {
+	msgs []string
+}
+
+func (p *recordProcessor) Process(_ context.Context, entry *domain.Entry) error {
+	msg, _ := entry.Get("msg")
+	p.msgs = append(p.msgs, msg.(string))
+	return nil
+}
+
+// countEmitter skips every entry and emits how many it skipped at the end.
+type countEmitter struct {
+	count int
+}
+
+func (p *countEmitter) Process(context.Context, *domain.Entry) error {
+	p.count++
+	return ErrSkipEntry
+}
+
+func (p *countEmitter) Emit(_ context.Context, final bool) []*domain.Entry {
+	if !final {
+		return nil
+	}
+	entry := domain.NewEntry()
+	entry.Set("msg", "skipped "+strconv.Itoa(p.count))
+	return []*domain.Entry{entry}
+}
+
+// chanFetcher reads the messages from a channel, until it is closed.
+type chanFetcher chan string
+
+func (f chanFetcher) Next() (domain.Entry, error) {
+	msg, ok := <-f
+	if !ok {
+		return domain.Entry{}, io.EOF
+	}
+	entry := domain.NewEntry()
+	entry.Set("msg", msg)
+	return *entry, nil
+}
+
+// chanProcessor sends the messages to a channel.
+type chanProcessor chan string
+
+func (p chanProcessor) Process(_ context.Context, entry *domain.Entry) error {
+	msg, _ := entry.Get("msg")
+	p <- msg.(string)
+	return nil
+}
+
+// idleEmitter skips every entry and emits one once no entry came in for a
+// while.
+type idleEmitter struct {
+	mu      sync.Mutex
+	last    time.Time
+	pending bool
+}
+
+func (p *idleEmitter) Process(context.Context, *domain.Entry) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.last, p.pending = time.Now(), true
+	return ErrSkipEntry
+}
+
+func (p *idleEmitter) Emit(_ context.Context, final bool) []*domain.Entry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.pending || !final && time.Since(p.last) < 20*time.Millisecond {
+		return nil
+	}
+	p.pending = false
+	entry := domain.NewEntry()
+	entry.Set("msg", "idle")
+	return []*domain.Entry{entry}
+}
+
+func TestEntriesReader_Start(t *testing.T) {
+	t.Run("should run emitted entries through the following processors", func(t *testing.T) {
+		before, after := &recordProcessor{}, &recordProcessor{}
+		fetcher := &sliceFetcher{"a", "b", "c"}
+		r := NewEntriesReader(fetcher, func(_ context.Context, err error) error {
+			return err
+		})
+
+		err := r.Start(context.Background(), before, &countEmitter{}, after)
+		require.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, []string{"a", "b", "c"}, before.msgs)
+		assert.Equal(t, []string{"skipped 3"}, after.msgs)
+	})
+
+	t.Run("should poll the emitters while no entry comes in", func(t *testing.T) {
+		for _, workers := range []int{1, 4} {
+			fetcher, after := make(chanFetcher), make(chanProcessor, 1)
+			r := NewEntriesReader(fetcher, func(_ context.Context, err error) error {
+				return err
+			}, WithWorkers(workers), WithEmitInterval(time.Millisecond))
+
+			done := make(chan error, 1)
+			go func() {
+				done <- r.Start(context.Background(), &idleEmitter{}, after)
+			}()
+			fetcher <- "a"
+			select {
+			case msg := <-after:
+				assert.Equal(t, "idle", msg)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("workers=%d: nothing emitted while the input was quiet", workers)
+			}
+			close(fetcher)
+			require.ErrorIs(t, <-done, io.EOF)
+		}
+	})
+}
+
+// lineFetcher splits reading from parsing; lines starting with "!" fail to
+// parse.
+type lineFetcher struct {
+	sliceFetcher
+	n int
+}
+
+func (f *lineFetcher) NextLine() ([]byte, int, error) {
+	if len(f.sliceFetcher) == 0 {
+		return nil, 0, io.EOF
+	}
+	line := f.sliceFetcher[0]
+	f.sliceFetcher = f.sliceFetcher[1:]
+	f.n++
+	return []byte(line), f.n, nil
+}
+
+func (f *lineFetcher) ParseLine(line []byte, lineNumber int) (domain.Entry, error) {
+	if strings.HasPrefix(string(line), "!") {
+		return domain.Entry{}, fmt.Errorf("invalid line %d", lineNumber)
+	}
+	entry := domain.NewEntry()
+	entry.Set("msg", string(line))
+	return *entry, nil
+}
+
+// shuffleProcessor takes a random time on each entry, skipping those whose
+// message ends with "x".
+type shuffleProcessor struct{}
+
+func (shuffleProcessor) ConcurrentSafe() {}
+
+func (shuffleProcessor) Process(_ context.Context, entry *domain.Entry) error {
+	time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+	msg, _ := entry.Get("msg")
+	if strings.HasSuffix(msg.(string), "x") {
+		return ErrSkipEntry
+	}
+	return nil
+}
+
+func TestEntriesReader_Start_workers(t *testing.T) {
+	newLines := func(n int) ([]string, []string) {
+		lines := make([]string, n)
+		var kept []string
+		for i := range lines {
+			lines[i] = strconv.Itoa(i)
+			if i%7 == 0 {
+				lines[i] += "x"
+			} else {
+				kept = append(kept, lines[i])
+			}
+		}
+		return lines, kept
+	}
+
+	t.Run("should keep the input order for the sequential processors", func(t *testing.T) {
+		lines, kept := newLines(2000)
+		for _, fetcher := range []EntryFetcher{(*sliceFetcher)(&lines), &lineFetcher{sliceFetcher: append(sliceFetcher(nil), lines...)}} {
+			before, after := &recordProcessor{}, &recordProcessor{}
+			r := NewEntriesReader(fetcher, func(_ context.Context, err error) error {
+				return err
+			}, WithWorkers(8))
+
+			err := r.Start(context.Background(), shuffleProcessor{}, before, shuffleProcessor{}, after)
+			require.ErrorIs(t, err, io.EOF)
+			assert.Equal(t, kept, before.msgs)
+			assert.Equal(t, kept, after.msgs)
+		}
+	})
+
+	t.Run("should run emitted entries through the following processors", func(t *testing.T) {
+		lines, kept := newLines(100)
+		after := &recordProcessor{}
+		r := NewEntriesReader(&lineFetcher{sliceFetcher: lines}, func(_ context.Context, err error) error {
+			return err
+		}, WithWorkers(4))
+
+		err := r.Start(context.Background(), shuffleProcessor{}, &countEmitter{}, shuffleProcessor{}, after)
+		require.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, []string{"skipped " + strconv.Itoa(len(kept))}, after.msgs)
+	})
+
+	t.Run("should hand parse errors to the error handler", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			errors []string
+		)
+		after := &recordProcessor{}
+		r := NewEntriesReader(&lineFetcher{sliceFetcher: sliceFetcher{"a", "!b", "c"}}, func(_ context.Context, err error) error {
+			mu.Lock()
+			defer mu.Unlock()
+			errors = append(errors, err.Error())
+			return nil
+		}, WithWorkers(4))
+
+		err := r.Start(context.Background(), after)
+		require.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, []string{"a", "c"}, after.msgs)
+		assert.Equal(t, []string{"invalid line 2"}, errors)
+	})
+
+	t.Run("should stop on the errors the handler returns", func(t *testing.T) {
+		lines, _ := newLines(10000)
+		wantErr := fmt.Errorf("broken")
+		r := NewEntriesReader(&lineFetcher{sliceFetcher: append(lines[:10:10], "!")}, func(_ context.Context, err error) error {
+			return wantErr
+		}, WithWorkers(4))
+
+		err := r.Start(context.Background(), shuffleProcessor{}, &recordProcessor{})
+		require.ErrorIs(t, err, wantErr)
+	})
+}
+
+func Test_splitStages(t *testing.T) {
+	t.Run("should keep the processors after an emitter sequential", func(t *testing.T) {
+		emitter := &countEmitter{}
+		stages := splitStages([]EntryProcessor{shuffleProcessor{}, emitter, shuffleProcessor{}})
+		assert.Equal(t, []pipelineStage{
+			{processors: []EntryProcessor{shuffleProcessor{}}, concurrent: true},
+			{processors: []EntryProcessor{emitter, shuffleProcessor{}}},
+		}, stages)
+	})
+}
@@ -0,0 +1,558 @@
+package entryreader
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/document"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/numeric"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+// evalNode is a compiled query clause, matched against a single document.
+type evalNode interface {
+	match(doc *evalDoc) bool
+}
+
+// compileQuery compiles a bleve query for matching documents one at a time,
+// with the semantics of the bleve searchers on in-memory indexes: same
+// analyzers, same term matching for wildcards, regular expressions and fuzzy
+// terms, and the same handling of clauses matching nothing. It returns a nil
+// node for queries that can never match (bleve's MatchNoneSearcher), which
+// boolean and query string clauses ignore instead of failing on.
+func compileQuery(q query.Query, m mapping.IndexMapping) (evalNode, error) {
+	switch qq := q.(type) {
+	case *query.MatchAllQuery:
+		return matchAllNode{}, nil
+	case *query.MatchNoneQuery:
+		return nil, nil
+	case *query.BooleanQuery:
+		return compileBoolean(qq, m)
+	case *query.ConjunctionQuery:
+		nodes := make(conjunctionNode, 0, len(qq.Conjuncts))
+		for _, c := range qq.Conjuncts {
+			node, err := compileQuery(c, m)
+			if err != nil {
+				return nil, err
+			}
+			if node == nil {
+				if queryStringMode(qq) {
+					continue
+				}
+				node = matchNoneNode{}
+			}
+			nodes = append(nodes, node)
+		}
+		if len(nodes) == 0 {
+			return nil, nil
+		}
+		return nodes, nil
+	case *query.DisjunctionQuery:
+		nodes := make([]evalNode, 0, len(qq.Disjuncts))
+		for _, d := range qq.Disjuncts {
+			node, err := compileQuery(d, m)
+			if err != nil {
+				return nil, err
+			}
+			if node == nil {
+				if queryStringMode(qq) {
+					continue
+				}
+				node = matchNoneNode{}
+			}
+			nodes = append(nodes, node)
+		}
+		if len(nodes) == 0 {
+			return nil, nil
+		}
+		return disjunctionNode{nodes: nodes, min: int(qq.Min)}, nil
+	case *query.MatchQuery:
+		return compileMatch(qq, m)
+	case *query.MatchPhraseQuery:
+		if qq.Fuzziness != 0 {
+			return nil, fmt.Errorf("fuzzy phrases are not supported")
+		}
+		field := fieldOrDefault(qq.FieldVal, m)
+		tokens, err := analyze(qq.MatchPhrase, qq.Analyzer, field, m)
+		if err != nil || len(tokens) == 0 {
+			return nil, err
+		}
+		return phraseNode{field: field, terms: tokenStreamToPhrase(tokens)}, nil
+	case *query.MultiPhraseQuery:
+		return phraseNode{field: fieldOrDefault(qq.FieldVal, m), terms: qq.Terms}, nil
+	case *query.PhraseQuery:
+		terms := make([][]string, len(qq.Terms))
+		for i, t := range qq.Terms {
+			terms[i] = []string{t}
+		}
+		return phraseNode{field: fieldOrDefault(qq.FieldVal, m), terms: terms}, nil
+	case *query.TermQuery:
+		return termNode{field: fieldOrDefault(qq.FieldVal, m), term: qq.Term}, nil
+	case *query.PrefixQuery:
+		return prefixNode{field: fieldOrDefault(qq.FieldVal, m), prefix: qq.Prefix}, nil
+	case *query.FuzzyQuery:
+		return compileFuzzy(fieldOrDefault(qq.FieldVal, m), qq.Term, qq.Prefix, qq.Fuzziness)
+	case *query.WildcardQuery:
+		return compileRegexp(fieldOrDefault(qq.FieldVal, m), wildcardRegexpReplacer.Replace(qq.Wildcard))
+	case *query.RegexpQuery:
+		return compileRegexp(fieldOrDefault(qq.FieldVal, m), strings.TrimPrefix(qq.Regexp, "^"))
+	case *query.NumericRangeQuery:
+		return newRangeNode(fieldOrDefault(qq.FieldVal, m), qq.Min, qq.Max, qq.InclusiveMin, qq.InclusiveMax), nil
+	case *query.DateRangeQuery:
+		min, max := math.Inf(-1), math.Inf(1)
+		if !qq.Start.IsZero() {
+			min = numeric.Int64ToFloat64(qq.Start.UnixNano())
+		}
+		if !qq.End.IsZero() {
+			max = numeric.Int64ToFloat64(qq.End.UnixNano())
+		}
+		return newRangeNode(fieldOrDefault(qq.FieldVal, m), &min, &max, qq.InclusiveStart, qq.InclusiveEnd), nil
+	case *query.BoolFieldQuery:
+		term := "F"
+		if qq.Bool {
+			term = "T"
+		}
+		return termNode{field: fieldOrDefault(qq.FieldVal, m), term: term}, nil
+	default:
+		return nil, fmt.Errorf("unsupported query %T", q)
+	}
+}
+
+func compileBoolean(q *query.BooleanQuery, m mapping.IndexMapping) (evalNode, error) {
+	if q.Filter != nil {
+		return nil, fmt.Errorf("unsupported boolean filter")
+	}
+	var node booleanNode
+	var err error
+	if q.Must != nil {
+		if node.must, err = compileQuery(q.Must, m); err != nil {
+			return nil, err
+		}
+	}
+	if q.Should != nil {
+		if node.should, err = compileQuery(q.Should, m); err != nil {
+			return nil, err
+		}
+		if d, ok := node.should.(disjunctionNode); ok {
+			node.shouldMin = d.min
+		}
+	}
+	if q.MustNot != nil {
+		if node.mustNot, err = compileQuery(q.MustNot, m); err != nil {
+			return nil, err
+		}
+	}
+	if node.must == nil && node.should == nil && node.mustNot == nil {
+		return nil, nil
+	}
+	return node, nil
+}
+
+func compileMatch(q *query.MatchQuery, m mapping.IndexMapping) (evalNode, error) {
+	field := fieldOrDefault(q.FieldVal, m)
+	tokens, err := analyze(q.Match, q.Analyzer, field, m)
+	if err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+	nodes := make([]evalNode, len(tokens))
+	for i, token := range tokens {
+		if q.Fuzziness != 0 {
+			if nodes[i], err = compileFuzzy(field, string(token.Term), q.Prefix, q.Fuzziness); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		nodes[i] = termNode{field: field, term: string(token.Term)}
+	}
+	if q.Operator == query.MatchQueryOperatorAnd {
+		return conjunctionNode(nodes), nil
+	}
+	return disjunctionNode{nodes: nodes, min: 1}, nil
+}
+
+// maxFuzziness is bleve's limit (searcher.MaxFuzziness).
+const maxFuzziness = 2
+
+func compileFuzzy(field, term string, prefix, fuzziness int) (evalNode, error) {
+	if fuzziness > maxFuzziness {
+		return nil, fmt.Errorf("fuzziness exceeds max (%d)", maxFuzziness)
+	}
+	if fuzziness < 0 {
+		return nil, fmt.Errorf("invalid fuzziness, negative")
+	}
+	if fuzziness == 0 {
+		return termNode{field: field, term: term}, nil
+	}
+	// The prefix is a length in bytes, but cut at a rune boundary.
+	prefixTerm := ""
+	for i, r := range term {
+		if i >= prefix {
+			break
+		}
+		prefixTerm += string(r)
+	}
+	return fuzzyNode{field: field, term: term, prefix: prefixTerm, fuzziness: fuzziness}, nil
+}
+
+// wildcardRegexpReplacer converts wildcards into regular expressions, as
+// bleve's WildcardQuery does.
+var wildcardRegexpReplacer = strings.NewReplacer(
+	"+", `\+`,
+	"(", `\(`,
+	")", `\)`,
+	"^", `\^`,
+	"$", `\$`,
+	".", `\.`,
+	"{", `\{`,
+	"}", `\}`,
+	"[", `\[`,
+	"]", `\]`,
+	`|`, `\|`,
+	`\`, `\\`,
+	"*", ".*",
+	"?", ".")
+
+func compileRegexp(field, pattern string) (evalNode, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return regexpNode{field: field, re: re}, nil
+}
+
+func fieldOrDefault(field string, m mapping.IndexMapping) string {
+	if field == "" {
+		return m.DefaultSearchField()
+	}
+	return field
+}
+
+func analyze(text, analyzerName, field string, m mapping.IndexMapping) (analysis.TokenStream, error) {
+	if analyzerName == "" {
+		analyzerName = m.AnalyzerNameForPath(field)
+	}
+	analyzer := m.AnalyzerNamed(analyzerName)
+	if analyzer == nil {
+		return nil, fmt.Errorf("no analyzer named '%s' registered", analyzerName)
+	}
+	return analyzer.Analyze([]byte(text)), nil
+}
+
+// tokenStreamToPhrase lays the tokens out by position, leaving the gaps of
+// removed tokens (stop words) empty.
+func tokenStreamToPhrase(tokens analysis.TokenStream) [][]string {
+	first, last := math.MaxInt, 0
+	for _, token := range tokens {
+		first = min(first, token.Position)
+		last = max(last, token.Position)
+	}
+	phrase := make([][]string, last-first+1)
+	for _, token := range tokens {
+		pos := token.Position - first
+		phrase[pos] = append(phrase[pos], string(token.Term))
+	}
+	return phrase
+}
+
+// queryStringMode reports whether a conjunction or disjunction was produced
+// by the query string parser, which skips the clauses matching nothing. The
+// flag is not exported by bleve.
+func queryStringMode(q query.Query) bool {
+	f := reflect.ValueOf(q).Elem().FieldByName("queryStringMode")
+	return f.IsValid() && f.Bool()
+}
+
+// evalDoc is a document mapped and analyzed exactly as the index would do it,
+// without indexing it.
+type evalDoc struct {
+	// fields holds the terms of each field, including the _all composite.
+	fields map[string]index.TokenFrequencies
+}
+
+func newEvalDoc(m mapping.IndexMapping, id string, data interface{}) (*evalDoc, error) {
+	doc := document.NewDocument(id)
+	if err := m.MapDocument(doc, data); err != nil {
+		return nil, fmt.Errorf("error mapping the entry: %w", err)
+	}
+	d := &evalDoc{fields: make(map[string]index.TokenFrequencies)}
+	doc.VisitFields(func(field index.Field) {
+		if !field.Options().IsIndexed() {
+			return
+		}
+		field.Analyze()
+		if doc.HasComposite() && field.Name() != "_id" {
+			doc.VisitComposite(func(cf index.CompositeField) {
+				cf.Compose(field.Name(), field.AnalyzedLength(), field.AnalyzedTokenFrequencies())
+			})
+		}
+		// Array items are separate fields with the same name.
+		tf, ok := d.fields[field.Name()]
+		if !ok {
+			tf = make(index.TokenFrequencies)
+			d.fields[field.Name()] = tf
+		}
+		tf.MergeAll(field.Name(), field.AnalyzedTokenFrequencies())
+	})
+	doc.VisitComposite(func(cf index.CompositeField) {
+		d.fields[cf.Name()] = cf.AnalyzedTokenFrequencies()
+	})
+	return d, nil
+}
+
+type matchAllNode struct{}
+
+func (matchAllNode) match(*evalDoc) bool { return true }
+
+// matchNoneNode is a clause matching nothing that, unlike a nil node, is not
+// skipped (outside of query string clauses).
+type matchNoneNode struct{}
+
+func (matchNoneNode) match(*evalDoc) bool { return false }
+
+type conjunctionNode []evalNode
+
+func (n conjunctionNode) match(doc *evalDoc) bool {
+	for _, node := range n {
+		if !node.match(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+type disjunctionNode struct {
+	nodes []evalNode
+	min   int
+}
+
+func (n disjunctionNode) match(doc *evalDoc) bool {
+	need := max(n.min, 1)
+	matched := 0
+	for _, node := range n.nodes {
+		if node.match(doc) {
+			matched++
+			if matched >= need {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// booleanNode follows bleve's BooleanSearcher: the should clauses only
+// filter when there is no must clause or when they have a minimum; with only
+// must not clauses, everything else matches.
+type booleanNode struct {
+	must, should, mustNot evalNode
+	shouldMin             int
+}
+
+func (n booleanNode) match(doc *evalDoc) bool {
+	if n.mustNot != nil && n.mustNot.match(doc) {
+		return false
+	}
+	if n.must != nil {
+		if !n.must.match(doc) {
+			return false
+		}
+		return n.should == nil || n.shouldMin == 0 || n.should.match(doc)
+	}
+	if n.should != nil {
+		return n.should.match(doc)
+	}
+	return true
+}
+
+type termNode struct {
+	field, term string
+}
+
+func (n termNode) match(doc *evalDoc) bool {
+	_, ok := doc.fields[n.field][n.term]
+	return ok
+}
+
+type prefixNode struct {
+	field, prefix string
+}
+
+func (n prefixNode) match(doc *evalDoc) bool {
+	for term := range doc.fields[n.field] {
+		if strings.HasPrefix(term, n.prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// regexpNode matches the terms a regular expression matches entirely, as
+// bleve's RegexpSearcher does on in-memory indexes.
+type regexpNode struct {
+	field string
+	re    *regexp.Regexp
+}
+
+func (n regexpNode) match(doc *evalDoc) bool {
+	prefix, complete := n.re.LiteralPrefix()
+	if complete {
+		_, ok := doc.fields[n.field][prefix]
+		return ok
+	}
+	for term := range doc.fields[n.field] {
+		if !strings.HasPrefix(term, prefix) {
+			continue
+		}
+		loc := n.re.FindStringIndex(term)
+		if loc != nil && loc[0] == 0 && loc[1] == len(term) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyNode matches the terms within fuzziness edits of term, starting with
+// prefix.
+type fuzzyNode struct {
+	field, term, prefix string
+	fuzziness           int
+}
+
+func (n fuzzyNode) match(doc *evalDoc) bool {
+	for term := range doc.fields[n.field] {
+		if !strings.HasPrefix(term, n.prefix) {
+			continue
+		}
+		if d, exceeded := search.LevenshteinDistanceMax(n.term, term, n.fuzziness); !exceeded && d <= n.fuzziness {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeNode matches numeric and datetime values, both indexed as prefix
+// coded int64 terms.
+type rangeNode struct {
+	field    string
+	min, max int64
+}
+
+// newRangeNode computes the bounds as bleve's NumericRangeSearcher does.
+func newRangeNode(field string, min, max *float64, inclusiveMin, inclusiveMax *bool) rangeNode {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if min != nil {
+		lo = *min
+	}
+	if max != nil {
+		hi = *max
+	}
+	n := rangeNode{
+		field: field,
+		min:   numeric.Float64ToInt64(lo),
+		max:   numeric.Float64ToInt64(hi),
+	}
+	if inclusiveMin != nil && !*inclusiveMin && n.min != math.MaxInt64 {
+		n.min++
+	}
+	if (inclusiveMax == nil || !*inclusiveMax) && n.max != math.MinInt64 {
+		n.max--
+	}
+	return n
+}
+
+func (n rangeNode) match(doc *evalDoc) bool {
+	for term := range doc.fields[n.field] {
+		if valid, shift := numeric.ValidPrefixCodedTerm(term); !valid || shift != 0 {
+			continue
+		}
+		v, err := numeric.PrefixCoded(term).Int64()
+		if err == nil && v >= n.min && v <= n.max {
+			return true
+		}
+	}
+	return false
+}
+
+// phraseNode matches consecutive terms within a single field value. Empty
+// slots (removed stop words) match any term.
+type phraseNode struct {
+	field string
+	terms [][]string
+}
+
+func (n phraseNode) match(doc *evalDoc) bool {
+	tf := doc.fields[n.field]
+	if len(n.terms) == 0 {
+		return false
+	}
+	// Phrases never span fields, even in _all: try each source field.
+	seen := make(map[string]bool)
+	for _, term := range n.terms[0] {
+		freq, ok := tf[term]
+		if !ok {
+			continue
+		}
+		for _, loc := range freq.Locations {
+			if seen[loc.Field] {
+				continue
+			}
+			seen[loc.Field] = true
+			if phraseFrom(tf, loc.Field, n.terms, 0, nil) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// phraseFrom follows bleve's findPhrasePaths (without slop): positions start
+// at 1, so 0 means no previous term.
+func phraseFrom(tf index.TokenFrequencies, field string, terms [][]string, prevPos int, arrayPositions []uint64) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	car, cdr := terms[0], terms[1:]
+	if len(car) == 0 || (len(car) == 1 && car[0] == "") {
+		next := prevPos + 1
+		if prevPos == 0 {
+			next = 0
+		}
+		return phraseFrom(tf, field, cdr, next, arrayPositions)
+	}
+	for _, term := range car {
+		freq, ok := tf[term]
+		if !ok {
+			continue
+		}
+		for _, loc := range freq.Locations {
+			if loc.Field != field {
+				continue
+			}
+			if prevPos != 0 && (loc.Position != prevPos+1 || !equalArrayPositions(loc.ArrayPositions, arrayPositions)) {
+				continue
+			}
+			if phraseFrom(tf, field, cdr, loc.Position, loc.ArrayPositions) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func equalArrayPositions(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,341 @@
+package entryreader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/jamillosantos/lovr/internal/service/processors"
+)
+
+// ExplainNode is a clause of an explained query.
+type ExplainNode struct {
+	// Kind is one of and, or, not, optional (only affects ranking), match,
+	// phrase, term, prefix, wildcard, regexp, fuzzy, range, bool, all and
+	// none.
+	Kind string
+	// Field is the searched field; _all searches every field.
+	Field string
+	// Value is the searched text, term or pattern, or the bounds of ranges in
+	// [from TO to] notation.
+	Value string
+	// Terms are the analyzed terms of match and phrase clauses: what the
+	// index is actually searched for, lowercased and without stop words.
+	Terms []string
+	// Operator tells whether a match clause requires all its terms (and) or
+	// any of them (or).
+	Operator string
+	// Min is the number of children an or clause requires, when above one.
+	Min       int
+	Fuzziness int
+	// Hits is the number of entries matching the clause on its own. It is
+	// only counted by Reader.Explain.
+	Hits     *uint64
+	Children []*ExplainNode
+
+	query query.Query
+}
+
+// Explanation is a query as understood by the search.
+type Explanation struct {
+	Query string
+	// Tree is nil for empty queries, which match every entry, and for
+	// invalid ones.
+	Tree *ExplainNode
+	// Error is set for invalid queries.
+	Error *QuerySyntaxError
+}
+
+// Explain parses the query as the search and --filter do, returning the
+// clause tree without hit counts.
+func Explain(q string) (Explanation, error) {
+	return explain(q, processors.NewIndexMapping())
+}
+
+// Explain parses the query and counts the entries matching each clause.
+func (r *Reader) Explain(ctx context.Context, q string) (Explanation, error) {
+	e, err := explain(q, r.index.Mapping())
+	if err != nil || e.Tree == nil {
+		return e, err
+	}
+	if err := r.countHits(ctx, e.Tree); err != nil {
+		return Explanation{}, err
+	}
+	return e, nil
+}
+
+func (r *Reader) countHits(ctx context.Context, node *ExplainNode) error {
+	request := bleve.NewSearchRequestOptions(node.query, 0, 0, false)
+	result, err := r.index.SearchInContext(ctx, request)
+	if err != nil {
+		return fmt.Errorf("error counting the hits of %s: %w", node.label(), err)
+	}
+	hits := result.Total
+	node.Hits = &hits
+	for _, child := range node.Children {
+		if err := r.countHits(ctx, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func explain(q string, m mapping.IndexMapping) (Explanation, error) {
+	e := Explanation{Query: q}
+	built, err := buildQuery(q)
+	var syntaxErr *QuerySyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		e.Error = syntaxErr
+		return e, nil
+	case err != nil:
+		return Explanation{}, err
+	case built == nil:
+		return e, nil
+	}
+	if e.Tree, err = explainQuery(built, m); err != nil {
+		return Explanation{}, err
+	}
+	return e, nil
+}
+
+func explainQuery(q query.Query, m mapping.IndexMapping) (*ExplainNode, error) {
+	n := &ExplainNode{query: q}
+	switch qq := q.(type) {
+	case *query.MatchAllQuery:
+		n.Kind = "all"
+	case *query.MatchNoneQuery:
+		n.Kind = "none"
+	case *query.ConjunctionQuery:
+		n.Kind = "and"
+		return n, n.explainChildren(qq.Conjuncts, m)
+	case *query.DisjunctionQuery:
+		n.Kind = "or"
+		if qq.Min > 1 {
+			n.Min = int(qq.Min)
+		}
+		return n, n.explainChildren(qq.Disjuncts, m)
+	case *query.BooleanQuery:
+		return explainBoolean(qq, m)
+	case *query.MatchQuery:
+		n.Kind, n.Field, n.Value, n.Fuzziness = "match", qq.FieldVal, qq.Match, qq.Fuzziness
+		n.Operator = "or"
+		if qq.Operator == query.MatchQueryOperatorAnd {
+			n.Operator = "and"
+		}
+		return n, n.analyze(qq.Match, qq.Analyzer, m)
+	case *query.MatchPhraseQuery:
+		n.Kind, n.Field, n.Value, n.Fuzziness = "phrase", qq.FieldVal, qq.MatchPhrase, qq.Fuzziness
+		return n, n.analyze(qq.MatchPhrase, qq.Analyzer, m)
+	case *query.PhraseQuery:
+		n.Kind, n.Field, n.Terms = "phrase", qq.FieldVal, qq.Terms
+		n.Value = strings.Join(qq.Terms, " ")
+	case *query.MultiPhraseQuery:
+		n.Kind, n.Field = "phrase", qq.FieldVal
+		for _, alternatives := range qq.Terms {
+			n.Terms = append(n.Terms, strings.Join(alternatives, "|"))
+		}
+		n.Value = strings.Join(n.Terms, " ")
+	case *query.TermQuery:
+		n.Kind, n.Field, n.Value = "term", qq.FieldVal, qq.Term
+	case *query.PrefixQuery:
+		n.Kind, n.Field, n.Value = "prefix", qq.FieldVal, qq.Prefix
+	case *query.WildcardQuery:
+		n.Kind, n.Field, n.Value = "wildcard", qq.FieldVal, qq.Wildcard
+	case *query.RegexpQuery:
+		n.Kind, n.Field, n.Value = "regexp", qq.FieldVal, qq.Regexp
+	case *query.FuzzyQuery:
+		n.Kind, n.Field, n.Value, n.Fuzziness = "fuzzy", qq.FieldVal, qq.Term, qq.Fuzziness
+	case *query.NumericRangeQuery:
+		n.Kind, n.Field = "range", qq.FieldVal
+		n.Value = formatRange(formatBound(qq.Min), formatBound(qq.Max), qq.InclusiveMin, qq.InclusiveMax)
+	case *query.DateRangeQuery:
+		n.Kind, n.Field = "range", qq.FieldVal
+		n.Value = formatRange(formatTime(qq.Start.Time), formatTime(qq.End.Time), qq.InclusiveStart, qq.InclusiveEnd)
+	case *query.BoolFieldQuery:
+		n.Kind, n.Field, n.Value = "bool", qq.FieldVal, strconv.FormatBool(qq.Bool)
+	default:
+		return nil, fmt.Errorf("unsupported query %T", q)
+	}
+	return n, nil
+}
+
+// explainBoolean lays out bleve's must/should/must not clauses as and, or
+// and not nodes.
+func explainBoolean(q *query.BooleanQuery, m mapping.IndexMapping) (*ExplainNode, error) {
+	n := &ExplainNode{Kind: "and", query: q}
+	if conj, ok := q.Must.(*query.ConjunctionQuery); ok {
+		if err := n.explainChildren(conj.Conjuncts, m); err != nil {
+			return nil, err
+		}
+	} else if q.Must != nil {
+		if err := n.explainChildren([]query.Query{q.Must}, m); err != nil {
+			return nil, err
+		}
+	}
+	if q.Should != nil {
+		should, err := explainQuery(q.Should, m)
+		if err != nil {
+			return nil, err
+		}
+		if should.Kind == "or" && q.Must != nil && should.Min == 0 {
+			// Alongside required clauses, bleve only uses them for ranking.
+			should.Kind = "optional"
+		}
+		n.Children = append(n.Children, should)
+	}
+	if disj, ok := q.MustNot.(*query.DisjunctionQuery); ok {
+		for _, d := range disj.Disjuncts {
+			child, err := explainQuery(d, m)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, &ExplainNode{
+				Kind:     "not",
+				Children: []*ExplainNode{child},
+				query:    query.NewBooleanQuery(nil, nil, []query.Query{d}),
+			})
+		}
+	}
+	if len(n.Children) == 1 && n.Children[0].Kind != "optional" {
+		return n.Children[0], nil
+	}
+	return n, nil
+}
+
+func (n *ExplainNode) explainChildren(qs []query.Query, m mapping.IndexMapping) error {
+	for _, q := range qs {
+		child, err := explainQuery(q, m)
+		if err != nil {
+			return err
+		}
+		n.Children = append(n.Children, child)
+	}
+	return nil
+}
+
+func (n *ExplainNode) analyze(text, analyzerName string, m mapping.IndexMapping) error {
+	tokens, err := analyze(text, analyzerName, fieldOrDefault(n.Field, m), m)
+	if err != nil {
+		return err
+	}
+	n.Terms = make([]string, len(tokens))
+	for i, token := range tokens {
+		n.Terms[i] = string(token.Term)
+	}
+	return nil
+}
+
+func formatBound(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'g', -1, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// formatRange renders bounds with bleve's defaults: the lower bound is
+// inclusive and the upper one exclusive unless told otherwise.
+func formatRange(from, to string, inclusiveFrom, inclusiveTo *bool) string {
+	open, closing := "[", "}"
+	if inclusiveFrom != nil && !*inclusiveFrom {
+		open = "{"
+	}
+	if inclusiveTo != nil && *inclusiveTo {
+		closing = "]"
+	}
+	if from == "" {
+		from = "*"
+	}
+	if to == "" {
+		to = "*"
+	}
+	return open + from + " TO " + to + closing
+}
+
+// Text renders the explanation for humans: one clause per line, indented
+// by depth, or the syntax error pointed at.
+func (e Explanation) Text() string {
+	var b strings.Builder
+	switch {
+	case e.Error != nil:
+		b.WriteString(e.Query + "\n")
+		b.WriteString(strings.Repeat(" ", e.Error.Pos) + "^\n")
+		b.WriteString(e.Error.Error() + "\n")
+	case e.Tree == nil:
+		b.WriteString("empty query: matches every entry\n")
+	default:
+		e.Tree.writeText(&b, 0)
+	}
+	return b.String()
+}
+
+func (n *ExplainNode) writeText(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(n.label())
+	if n.Hits != nil {
+		fmt.Fprintf(b, "  (%d hits)", *n.Hits)
+	}
+	b.WriteString("\n")
+	for _, child := range n.Children {
+		child.writeText(b, depth+1)
+	}
+}
+
+func (n *ExplainNode) label() string {
+	field := n.Field
+	if field == "" {
+		field = "_all"
+	}
+	switch n.Kind {
+	case "and", "not":
+		return strings.ToUpper(n.Kind)
+	case "or":
+		if n.Min > 1 {
+			return fmt.Sprintf("OR (at least %d)", n.Min)
+		}
+		return "OR"
+	case "optional":
+		return "OPTIONAL (only affects ranking)"
+	case "all":
+		return "MATCH ALL"
+	case "none":
+		return "MATCH NONE"
+	case "match", "phrase":
+		label := fmt.Sprintf("%s %s:%q", n.Kind, field, n.Value)
+		if n.Fuzziness > 0 {
+			label += fmt.Sprintf("~%d", n.Fuzziness)
+		}
+		switch {
+		case len(n.Terms) == 0:
+			return label + " -> no searchable terms"
+		case n.Kind == "phrase":
+			return label + " -> terms in order: " + strings.Join(n.Terms, " ")
+		case n.Operator == "and" && len(n.Terms) > 1:
+			return label + " -> all of: " + strings.Join(n.Terms, " ")
+		case len(n.Terms) > 1:
+			return label + " -> any of: " + strings.Join(n.Terms, " ")
+		default:
+			return label + " -> term: " + n.Terms[0]
+		}
+	case "regexp":
+		return fmt.Sprintf("regexp %s:/%s/", field, n.Value)
+	case "fuzzy":
+		return fmt.Sprintf("fuzzy %s:%s~%d", field, n.Value, n.Fuzziness)
+	default:
+		return fmt.Sprintf("%s %s:%s", n.Kind, field, n.Value)
+	}
+}
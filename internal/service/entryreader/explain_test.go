@@ -0,0 +1,154 @@
+package entryreader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+	"github.com/jamillosantos/lovr/internal/service/processors"
+)
+
+func TestExplain(t *testing.T) {
+	t.Run("should return the clause tree", func(t *testing.T) {
+		got, err := entryreader.Explain("level:(error OR fatal) -route:/health")
+		require.NoError(t, err)
+		require.Nil(t, got.Error)
+		require.NotNil(t, got.Tree)
+		assert.Equal(t, "and", got.Tree.Kind)
+		require.Len(t, got.Tree.Children, 2)
+
+		or := got.Tree.Children[0]
+		assert.Equal(t, "or", or.Kind)
+		require.Len(t, or.Children, 2)
+		assert.Equal(t, "match", or.Children[0].Kind)
+		assert.Equal(t, "level", or.Children[0].Field)
+		assert.Equal(t, []string{"error"}, or.Children[0].Terms)
+
+		not := got.Tree.Children[1]
+		assert.Equal(t, "not", not.Kind)
+		require.Len(t, not.Children, 1)
+		assert.Equal(t, "route", not.Children[0].Field)
+		assert.Equal(t, "/health", not.Children[0].Value)
+		assert.Equal(t, []string{"health"}, not.Children[0].Terms)
+	})
+
+	t.Run("should show the analyzed terms", func(t *testing.T) {
+		got, err := entryreader.Explain(`"The Cache" the`)
+		require.NoError(t, err)
+		require.NotNil(t, got.Tree)
+		require.Len(t, got.Tree.Children, 2)
+		assert.Equal(t, "phrase", got.Tree.Children[0].Kind)
+		assert.Equal(t, []string{"cache"}, got.Tree.Children[0].Terms)
+		assert.Empty(t, got.Tree.Children[1].Terms)
+		assert.Contains(t, got.Text(), "match _all:\"the\" -> no searchable terms")
+	})
+
+	t.Run("should render ranges in bracket notation", func(t *testing.T) {
+		got, err := entryreader.Explain("status:{500 TO *]")
+		require.NoError(t, err)
+		require.NotNil(t, got.Tree)
+		// The numeric values and the numeric strings of the field.
+		assert.Equal(t, "or", got.Tree.Kind)
+		require.Len(t, got.Tree.Children, 2)
+		assert.Equal(t, "range", got.Tree.Children[0].Kind)
+		assert.Equal(t, "status", got.Tree.Children[0].Field)
+		assert.Equal(t, "{500 TO *]", got.Tree.Children[0].Value)
+		assert.Equal(t, "status._num", got.Tree.Children[1].Field)
+	})
+
+	t.Run("should render the tree as indented text", func(t *testing.T) {
+		got, err := entryreader.Explain("+level:error timeout")
+		require.NoError(t, err)
+		assert.Equal(t, "AND\n"+
+			"  match level:\"error\" -> term: error\n"+
+			"  match _all:\"timeout\" -> term: timeout\n", got.Text())
+	})
+
+	t.Run("should explain empty queries", func(t *testing.T) {
+		got, err := entryreader.Explain("  ")
+		require.NoError(t, err)
+		assert.Nil(t, got.Tree)
+		assert.Nil(t, got.Error)
+		assert.Equal(t, "empty query: matches every entry\n", got.Text())
+	})
+
+	t.Run("should report syntax errors with their position", func(t *testing.T) {
+		for _, tc := range []struct {
+			query string
+			pos   int
+		}{
+			{query: "a OR b) c", pos: 6},
+			{query: "level:(error", pos: 0},
+			{query: "ok /[a-/", pos: 3},
+			{query: "é timestamp:>now-1x", pos: 2},
+		} {
+			got, err := entryreader.Explain(tc.query)
+			require.NoError(t, err, tc.query)
+			require.NotNil(t, got.Error, tc.query)
+			assert.Equal(t, tc.pos, got.Error.Pos, tc.query)
+			assert.Nil(t, got.Tree, tc.query)
+		}
+	})
+
+	t.Run("should point at the error in the text", func(t *testing.T) {
+		got, err := entryreader.Explain("a OR b) c")
+		require.NoError(t, err)
+		assert.Equal(t, "a OR b) c\n      ^\ninvalid query: unexpected \")\"\n", got.Text())
+	})
+}
+
+func TestReader_Explain(t *testing.T) {
+	ctx := context.Background()
+
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+
+	indexer := processors.NewIndexer(index)
+	for _, l := range [][2]string{
+		{"error", "connection timeout"},
+		{"error", "disk full"},
+		{"info", "request timeout"},
+		{"debug", "cache miss"},
+	} {
+		entry := domain.NewEntry()
+		entry.Set("level", l[0])
+		entry.Set("msg", l[1])
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	reader := entryreader.NewReader(index)
+
+	t.Run("should count the hits of each clause", func(t *testing.T) {
+		got, err := reader.Explain(ctx, "level:error timeout -message:disk")
+		require.NoError(t, err)
+		require.NotNil(t, got.Tree)
+		hits := func(n *entryreader.ExplainNode) uint64 {
+			require.NotNil(t, n.Hits)
+			return *n.Hits
+		}
+		assert.Equal(t, uint64(1), hits(got.Tree))
+		require.Len(t, got.Tree.Children, 3)
+		assert.Equal(t, uint64(2), hits(got.Tree.Children[0]))
+		assert.Equal(t, uint64(2), hits(got.Tree.Children[1]))
+		assert.Equal(t, uint64(3), hits(got.Tree.Children[2]))
+		assert.Equal(t, uint64(1), hits(got.Tree.Children[2].Children[0]))
+		assert.Contains(t, got.Text(), "match level:\"error\" -> term: error  (2 hits)")
+	})
+
+	t.Run("should return syntax errors without counting", func(t *testing.T) {
+		got, err := reader.Explain(ctx, "level:(error")
+		require.NoError(t, err)
+		require.NotNil(t, got.Error)
+		assert.Equal(t, "invalid query: missing closing parenthesis in level:value list", got.Error.Error())
+		assert.Nil(t, got.Tree)
+	})
+}
@@ -2,6 +2,7 @@ package entryreader
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -17,27 +18,66 @@ var internalFields = map[string]struct{}{
 	"_all":                         {},
 	"_id":                          {},
 	processors.FieldTimestampNanos: {},
+	processors.FieldRaw:            {},
 }
 
-// Fields returns the names of the fields available for searching, sorted
-// alphabetically.
-func (r *Reader) Fields(_ context.Context) ([]string, error) {
+// Field is a field available for searching.
+type Field struct {
+	Name string
+	// Types are the types of the values indexed under the field (see
+	// processors.FieldTypes), sorted. Empty for fields the indexer did not
+	// record, such as the timestamp.
+	Types []string
+}
+
+// Conflict reports whether the field was indexed with different types, so
+// that some queries only match part of its values: range queries skip the
+// values that are not numbers, numeric strings aside.
+func (f Field) Conflict() bool {
+	return len(f.Types) > 1
+}
+
+// Fields returns the fields available for searching, sorted alphabetically,
+// with the types their values were indexed as.
+func (r *Reader) Fields(_ context.Context) ([]Field, error) {
 	fields, err := r.index.Fields()
 	if err != nil {
 		return nil, fmt.Errorf("error listing fields: %w", err)
 	}
+	types, err := r.fieldTypes()
+	if err != nil {
+		return nil, err
+	}
 
-	result := make([]string, 0, len(fields))
+	result := make([]Field, 0, len(fields))
 	for _, f := range fields {
-		if _, internal := internalFields[f]; internal {
+		if _, internal := internalFields[f]; internal || processors.IsSubField(f) {
 			continue
 		}
-		result = append(result, f)
+		result = append(result, Field{Name: f, Types: types[f]})
 	}
-	sort.Strings(result)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
 	return result, nil
 }
 
+// fieldTypes reads the field types saved by processors.Indexer.
+func (r *Reader) fieldTypes() (processors.FieldTypes, error) {
+	data, err := r.index.GetInternal(processors.FieldTypesKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the field types: %w", err)
+	}
+	var types processors.FieldTypes
+	if len(data) == 0 {
+		return types, nil
+	}
+	if err := json.Unmarshal(data, &types); err != nil {
+		return nil, fmt.Errorf("error decoding the field types: %w", err)
+	}
+	return types, nil
+}
+
 type FieldValue struct {
 	Value string
 	Count uint64
@@ -43,7 +43,7 @@ type HistogramResponse struct {
 	Buckets []HistogramBucket
 }
 
-var levelOrder = []string{"debug", "info", "warning", "error", "fatal", "panic"}
+var levelOrder = []string{"trace", "debug", "info", "warning", "error", "fatal", "panic"}
 
 // Histogram counts matching entries into time buckets, optionally split by
 // the values of a field. bleve has no sub-aggregations, so the counts come
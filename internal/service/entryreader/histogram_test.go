@@ -38,9 +38,10 @@ func TestReader_Histogram(t *testing.T) {
 		m.Set("ts", base.Add(time.Duration(i)*time.Minute).Format(time.RFC3339))
 		m.Set("level", level)
 		m.Set("msg", fmt.Sprintf("m%d", i))
-		entry := domain.Entry(*m)
+		entry := domain.Entry{OrderedMap: *m}
 		require.NoError(t, indexer.Process(ctx, &entry))
 	}
+	require.NoError(t, indexer.Flush())
 
 	reader := entryreader.NewReader(index)
 
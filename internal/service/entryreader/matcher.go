@@ -4,20 +4,26 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/blevesearch/bleve/v2"
-	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/blevesearch/bleve/v2/mapping"
 
 	"github.com/jamillosantos/lovr/internal/domain"
 	"github.com/jamillosantos/lovr/internal/service/processors"
 )
 
-// Matcher evaluates the web UI search syntax against individual entries by
-// running each one through a private in-memory bleve index: index the entry,
-// run the query, drop the entry. Slower than a hand-written evaluator, but
-// the filter and the web search cannot drift apart.
+// Matcher evaluates the web UI search syntax against individual entries. The
+// query is parsed by the same code as the web search and each entry is mapped
+// and analyzed by the index mapping, but nothing is indexed: the query tree
+// is walked against the entry terms directly (see compileQuery). The
+// conformance tests run every query against both this and bleve, so the
+// filter and the web search cannot drift apart.
+//
+// Matcher is safe for concurrent use.
 type Matcher struct {
-	index bleve.Index
-	query query.Query
+	mapping mapping.IndexMapping
+	// empty is set for an empty expression, which matches everything.
+	empty bool
+	// node is nil when the query can never match.
+	node evalNode
 }
 
 func NewMatcher(expr string) (*Matcher, error) {
@@ -25,39 +31,38 @@ func NewMatcher(expr string) (*Matcher, error) {
 	if err != nil {
 		return nil, err
 	}
-	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
-	if err != nil {
-		return nil, fmt.Errorf("error creating the filter index: %w", err)
+	m := &Matcher{
+		mapping: processors.NewIndexMapping(),
+		empty:   q == nil,
+	}
+	if q != nil {
+		m.node, err = compileQuery(q, m.mapping)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %w", err)
+		}
 	}
-	return &Matcher{
-		index: index,
-		query: q,
-	}, nil
+	return m, nil
 }
 
-func (m *Matcher) Match(ctx context.Context, entry *domain.Entry) (bool, error) {
-	if m.query == nil {
+func (m *Matcher) Match(_ context.Context, entry *domain.Entry) (bool, error) {
+	if m.empty {
 		return true, nil
 	}
-	id, doc, err := processors.BuildDoc(entry)
+	if m.node == nil {
+		return false, nil
+	}
+	id, data, err := processors.BuildDoc(entry)
 	if err != nil {
 		return false, err
 	}
-	if err := m.index.Index(id, doc); err != nil {
-		return false, fmt.Errorf("error indexing the entry: %w", err)
-	}
-	defer func() {
-		_ = m.index.Delete(id)
-	}()
-
-	req := bleve.NewSearchRequestOptions(m.query, 1, 0, false)
-	result, err := m.index.SearchInContext(ctx, req)
+	doc, err := newEvalDoc(m.mapping, id, data)
 	if err != nil {
-		return false, fmt.Errorf("error matching the entry: %w", err)
+		return false, err
 	}
-	return result.Total > 0, nil
+	return m.node.match(doc), nil
 }
 
+// Close implements io.Closer. The matcher holds no resources.
 func (m *Matcher) Close() error {
-	return m.index.Close()
+	return nil
 }
@@ -0,0 +1,140 @@
+package entryreader_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+	"github.com/jamillosantos/lovr/internal/service/processors"
+)
+
+// conformanceEntries cover the value shapes the index maps differently:
+// text, keywords, numbers, booleans, date strings, quantities logged as
+// strings, nested objects, arrays, stop words and punctuation.
+var conformanceEntries = []string{
+	`{"ts":"2026-01-01T12:00:00Z","level":"error","msg":"connection timeout on upstream","route":"/api/v1/login","service":"api-gateway","status":504,"latency":1.25}`,
+	`{"ts":"2026-01-01T12:00:01Z","level":"info","msg":"request served","route":"/api/v1/users","service":"worker","status":200,"latency":0.012,"ok":true}`,
+	`{"ts":"2026-01-01T12:00:02Z","level":"WARN","msg":"The cache is cold","service":"cache","tags":["alpha beta","gamma"],"user":{"id":42,"name":"Ada Lovelace"}}`,
+	`{"ts":"2026-01-01T12:00:03Z","level":"debug","msg":"retrying in 500ms","attempt":3,"nested":{"host":"db1","port":5432},"created":"2025-06-01T00:00:00Z"}`,
+	`{"ts":"2026-01-01T12:00:04Z","level":"fatal","msg":"panic: index out of range","stacktrace":"main.go:12","ip":"10.0.0.1","trace_id":"4BF92F","ok":false}`,
+	`{"ts":"2026-01-01T12:00:05Z","level":"info","msg":"Connexion établie à São Paulo","service":"api-gateway","status":500,"tags":["beta"]}`,
+	`{"ts":"2026-01-01T12:00:06Z","msg":"no level here, just a message about the timeout","route":"/health","empty":""}`,
+	`{"ts":"2026-01-01T12:00:07Z","level":"error","msg":"alpha","other":"beta gamma","status":"500"}`,
+	`{"ts":"2026-01-01T12:00:09Z","level":"warn","msg":"upstream timeout after 250ms, retrying","service":"api-gateway"}`,
+	`{"ts":"2026-01-01T12:00:08Z","level":"info","msg":"upload done","latency":"152ms","size":"1.2MB","took":"1h30m","sizes":["512 KiB","3GB"],"status":"404"}`,
+}
+
+var conformanceQueries = []string{
+	// terms and fields
+	"timeout", "TIMEOUT", "connection timeout", "level:error", "level:ERROR",
+	"level:warning", "msg:timeout", "message:timeout", "service:api-gateway",
+	"service:gateway", "route:/api/v1/login", "route:/api/v1", "route:health",
+	"nested.host:db1", "user.name:ada", "user.id:42", "ip:10.0.0.1",
+	"trace_id:4BF92F", "trace_id:4bf92f", "sao", "são", "établie", "empty:x",
+	// stop words alone or mixed
+	"the", "the timeout", "+the error", "message:the",
+	// numbers
+	"500", "504", "status:500", "status:504", "status:>=500", "status:>499",
+	"status:<200", "status:<=200", "latency:>1", "latency:<0.1", "user.id:>40",
+	"attempt:3", "attempt:>=3 level:debug", "status:abc",
+	// booleans and dates
+	"ok:true", "ok:T", "created:>\"2025-01-01T00:00:00Z\"",
+	"created:<\"2025-01-01T00:00:00Z\"", "timestamp:>\"2026-01-01T12:00:03Z\"",
+	"timestamp:<=\"2026-01-01T12:00:01Z\"", "timestamp:>2026-01-01T12:00:03Z",
+	"timestamp:[2026-01-01T12:00:01Z TO 2026-01-01T12:00:03Z}", "created:<=2025-06-01",
+	"timestamp:<now-1h", "since:1h", "-since:2026-01-01T12:00:06Z", "status:[500 TO 504]",
+	"status:{500 TO *]", "latency:[* TO 1]", "user.id:[42 TO 42]",
+	// phrases
+	`"connection timeout"`, `"timeout connection"`, `message:"timeout on"`,
+	`"cache is cold"`, `"the cache"`, `"alpha beta"`, `"beta gamma"`,
+	`tags:"alpha beta"`, `"alpha gamma"`, `msg:"out of range"`,
+	// wildcards, regexps and fuzzy terms
+	"service:api*", "service:API*", "*gateway", "msg:*time*", "route:/api/*/login",
+	"tim?out", "/tim[a-z]+/", "msg:/ret.*/", "/a|ab/", "conect~1", "timeuot~1",
+	"timeuot~2", "level:eror~1", `msg:/retry.*/`, `/TIME.*/`, `-/t.*/`,
+	`message:/connection/ message:/tim.*/`, `message:/timeout after \d+ms/`,
+	`/TIMEOUT AFTER \d+/`, `message:/^upstream timeout/`, `-message:/after \d+ms, retrying$/`,
+	`msg:/^panic$/`, `/\d+ms/`, `level:(/e.*/ OR info)`,
+	// exact and case-sensitive values
+	"service:=api-gateway", "service:=API-gateway", "service:=api", "service:=api-*",
+	`msg:="The cache is cold"`, `msg:~The`, `msg:~the`, `msg:~"cache is"`, `msg:~Paulo`,
+	"msg:~Paul", "tags:=gamma", `tags:="alpha beta"`, "nested.host:=db1", "status:=500",
+	"-status:=500", "created:=2025-06-01T00:00:00Z", "trace_id:=4BF92F", "level:(=error OR =info)",
+	// existence
+	"_exists_:route", "_exists_:user.id", "_exists_:missing", "-_exists_:level",
+	"_exists_:tags",
+	// modifiers and boolean structure
+	"-level:info", "-level:info -level:debug", "+level:error -service:worker",
+	"+level:error timeout", "+timeout -route:/health", "-timeout",
+	"level:error OR level:fatal", "level:(error OR warning)", "level:(fatal OR debug)",
+	"(level:error OR level:info) service:api-gateway",
+	"(level:error OR level:info) -route:/api/v1/login",
+	"service:(api-gateway OR cache) status:>=500", "-service:(worker OR cache)",
+	"alpha OR gamma", "(alpha gamma) OR panic",
+	// quantities logged as strings
+	"latency:>100ms", "latency:<1s", "latency:>1", "latency:[0.01 TO 2]", "size:>1MB",
+	"size:[1MB TO 2MB]", "sizes:>1GB", "sizes:<1MiB", "took:>=90m", "status:[400 TO 500]",
+	"-latency:>100ms", "status:<=404",
+	// numbers, booleans and dates as text
+	"status:50*", "status:/5\\d+/", "created:2025*", "ok:false", "latency:1.25", "user.id:4*",
+	// AND, NOT and negated groups
+	"level:error AND timeout", "NOT level:info", "NOT level:error timeout",
+	"NOT level:error OR status:504", "NOT (level:error OR level:info)",
+	"-(level:error)", "-(level:error OR _exists_:tags) msg:the", "NOT NOT level:fatal",
+	"level:info AND NOT service:worker", "timeout AND -route:/health",
+}
+
+func TestMatcher_conformance(t *testing.T) {
+	ctx := context.Background()
+
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+
+	indexer := processors.NewIndexer(index)
+	entries := make([]*domain.Entry, len(conformanceEntries))
+	for i, line := range conformanceEntries {
+		entry := domain.NewEntry()
+		require.NoError(t, json.Unmarshal([]byte(line), &entry.OrderedMap), line)
+		entry.ID = fmt.Sprintf("e%d", i)
+		entry.Raw = line
+		entries[i] = entry
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+	reader := entryreader.NewReader(index)
+
+	for _, q := range conformanceQueries {
+		t.Run(q, func(t *testing.T) {
+			res, err := reader.Search(ctx, entryreader.SearchRequest{Query: q, PageSize: 200})
+			require.NoError(t, err)
+			want := make([]string, 0, len(res.Entries))
+			for _, e := range res.Entries {
+				want = append(want, e.ID)
+			}
+			sort.Strings(want)
+
+			m, err := entryreader.NewMatcher(q)
+			require.NoError(t, err)
+			got := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				ok, err := m.Match(ctx, entry)
+				require.NoError(t, err)
+				if ok {
+					got = append(got, entry.ID)
+				}
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
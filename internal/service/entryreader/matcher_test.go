@@ -2,6 +2,7 @@ package entryreader_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/iancoleman/orderedmap"
@@ -17,7 +18,7 @@ func matcherEntry(pairs ...string) *domain.Entry {
 	for i := 0; i+1 < len(pairs); i += 2 {
 		m.Set(pairs[i], pairs[i+1])
 	}
-	entry := domain.Entry(*m)
+	entry := domain.Entry{OrderedMap: *m}
 	return &entry
 }
 
@@ -92,6 +93,27 @@ func TestMatcher_Match(t *testing.T) {
 		}
 	})
 
+	t.Run("concurrent matches do not see each other's entries", func(t *testing.T) {
+		m, err := entryreader.NewMatcher("level:error")
+		require.NoError(t, err)
+		defer func() {
+			_ = m.Close()
+		}()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(entry *domain.Entry, want bool) {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					got, err := m.Match(ctx, entry)
+					assert.NoError(t, err)
+					assert.Equal(t, want, got)
+				}
+			}(map[bool]*domain.Entry{true: errEntry, false: infoEntry}[i%2 == 0], i%2 == 0)
+		}
+		wg.Wait()
+	})
+
 	t.Run("invalid query fails at construction", func(t *testing.T) {
 		_, err := entryreader.NewMatcher("level:(error")
 		require.Error(t, err)
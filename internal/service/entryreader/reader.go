@@ -2,13 +2,18 @@ package entryreader
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search"
 	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/iancoleman/orderedmap"
 
@@ -102,57 +107,71 @@ func (r *Reader) Search(ctx context.Context, req SearchRequest) (SearchResponse,
 
 	entries := make([]*domain.LogEntry, 0, len(result.Hits))
 	for _, hit := range result.Hits {
-		entry := &domain.LogEntry{
-			ID:     hit.ID,
-			Fields: *orderedmap.New(),
-		}
+		entries = append(entries, hitToLogEntry(hit))
+	}
 
-		keys := make([]string, 0, len(hit.Fields))
-		for k := range hit.Fields {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
+	return SearchResponse{
+		Count:    int64(result.Total),
+		Duration: result.Took,
+		Entries:  entries,
+	}, nil
+}
 
-		for _, k := range keys {
-			value := hit.Fields[k]
-			switch k {
-			case processors.FieldTimestampNanos:
-				if s, ok := value.(string); ok {
-					if nanos, err := strconv.ParseInt(s, 10, 64); err == nil {
-						entry.Timestamp = time.Unix(0, nanos).UTC()
-					}
-				}
-			case processors.FieldTimestamp:
-				// Fallback only: the stored datetime is truncated to seconds.
-				if entry.Timestamp.IsZero() {
-					if s, ok := value.(string); ok {
-						if ts, err := parseStoredTime(s); err == nil {
-							entry.Timestamp = ts
-						}
-					}
+// hitToLogEntry converts a hit loaded with all its stored fields back into
+// the entry BuildDoc indexed.
+func hitToLogEntry(hit *search.DocumentMatch) *domain.LogEntry {
+	entry := &domain.LogEntry{
+		ID:     hit.ID,
+		Fields: *orderedmap.New(),
+	}
+
+	keys := make([]string, 0, len(hit.Fields))
+	for k := range hit.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		value := hit.Fields[k]
+		switch k {
+		case processors.FieldTimestampNanos:
+			if s, ok := value.(string); ok {
+				if nanos, err := strconv.ParseInt(s, 10, 64); err == nil {
+					entry.Timestamp = time.Unix(0, nanos).UTC()
 				}
-			case processors.FieldMessage:
-				entry.Message, _ = value.(string)
-			case processors.FieldLevel:
+			}
+		case processors.FieldTimestamp:
+			// Fallback only: the stored datetime is truncated to seconds.
+			if entry.Timestamp.IsZero() {
 				if s, ok := value.(string); ok {
-					entry.Level = domain.Level(s)
+					if ts, err := parseStoredTime(s); err == nil {
+						entry.Timestamp = ts
+					}
 				}
-			case processors.FieldCaller:
-				entry.Caller, _ = value.(string)
-			case processors.FieldStacktrace:
-				entry.Stacktrace, _ = value.(string)
-			default:
-				entry.Fields.Set(k, value)
 			}
+		case processors.FieldMessage:
+			entry.Message, _ = value.(string)
+		case processors.FieldLevel:
+			if s, ok := value.(string); ok {
+				entry.Level = domain.Level(s)
+			}
+		case processors.FieldCaller:
+			entry.Caller, _ = value.(string)
+		case processors.FieldStacktrace:
+			entry.Stacktrace, _ = value.(string)
+		case processors.FieldRaw:
+			entry.Raw, _ = value.(string)
+		case processors.FieldTraceID:
+			entry.TraceID, _ = value.(string)
+		case processors.FieldSpanID:
+			entry.SpanID, _ = value.(string)
+		case processors.FieldParentSpanID:
+			entry.ParentSpanID, _ = value.(string)
+		default:
+			entry.Fields.Set(k, value)
 		}
-		entries = append(entries, entry)
 	}
-
-	return SearchResponse{
-		Count:    int64(result.Total),
-		Duration: result.Took,
-		Entries:  entries,
-	}, nil
+	return entry
 }
 
 func parseStoredTime(s string) (time.Time, error) {
@@ -166,56 +185,165 @@ func parseStoredTime(s string) (time.Time, error) {
 // buildQuery turns the user query into a bleve query with this grammar:
 //
 //	expr  := and (OR and)*
-//	and   := unit+          (implicit AND between units)
-//	unit  := '(' expr ')' | term
+//	and   := unary ([AND] unary)*      (AND is implied between units)
+//	unary := NOT unary | ['-'|'+'] '(' expr ')' | term
 //
-// The uppercase OR keyword separates alternatives, parentheses group for
-// precedence, and both are ignored inside double quotes.
+// NOT binds tighter than AND, which binds tighter than OR: NOT a b OR c is
+// ((NOT a) AND b) OR c. The keywords are uppercase only, parentheses group
+// for precedence and -( ) excludes a whole group; all of them are ignored
+// inside double quotes.
+//
+// Syntax errors are returned as *QuerySyntaxError.
 func buildQuery(q string) (query.Query, error) {
-	p := &queryParser{tokens: tokenizeQuery(q)}
+	tokens, offsets := tokenizeQuery(q)
+	p := &queryParser{
+		tokens:  tokens,
+		offsets: offsets,
+		length:  utf8.RuneCountInString(q),
+		now:     time.Now(),
+	}
 	built, err := p.parseExpr()
 	if err != nil {
 		return nil, err
 	}
 	if p.pos < len(p.tokens) {
-		return nil, fmt.Errorf("invalid query: unexpected %q", p.tokens[p.pos])
+		return nil, p.errorAt(p.pos, fmt.Errorf("invalid query: unexpected %q", p.tokens[p.pos]))
 	}
 	return built, nil
 }
 
+// QuerySyntaxError is a query that cannot be parsed. Pos is the offset, in
+// characters, of the token at fault, or the length of the query when it
+// ended too early.
+type QuerySyntaxError struct {
+	Pos int
+	Err error
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *QuerySyntaxError) Unwrap() error {
+	return e.Err
+}
+
 // tokenizeQuery splits the query into words, "(" and ")" tokens, keeping
-// quoted sections intact.
-func tokenizeQuery(q string) []string {
+// quoted sections, /regular expressions/ and [from TO to] ranges intact. It
+// also returns the offset, in characters, of each token.
+func tokenizeQuery(q string) ([]string, []int) {
 	tokens := make([]string, 0, 8)
+	offsets := make([]int, 0, 8)
 	var tok strings.Builder
+	start := 0
 	flush := func() {
 		if tok.Len() > 0 {
 			tokens = append(tokens, tok.String())
+			offsets = append(offsets, start)
 			tok.Reset()
 		}
 	}
+	runes := []rune(q)
 	inQuotes := false
-	for _, r := range q {
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if tok.Len() == 0 {
+			start = i
+		}
 		switch {
 		case r == '"':
 			inQuotes = !inQuotes
 			tok.WriteRune(r)
+		case !inQuotes && r == '/' && startsValue(tok.String()):
+			end := regexpEnd(runes, i)
+			if end < 0 {
+				tok.WriteRune(r)
+				continue
+			}
+			tok.WriteString(string(runes[i : end+1]))
+			i = end
+		case !inQuotes && (r == '[' || r == '{') && startsValue(tok.String()):
+			end := rangeEnd(runes, i)
+			if end < 0 {
+				tok.WriteRune(r)
+				continue
+			}
+			tok.WriteString(string(runes[i : end+1]))
+			i = end
 		case !inQuotes && (r == ' ' || r == '\t'):
 			flush()
 		case !inQuotes && (r == '(' || r == ')'):
 			flush()
 			tokens = append(tokens, string(r))
+			offsets = append(offsets, i)
 		default:
 			tok.WriteRune(r)
 		}
 	}
 	flush()
-	return tokens
+	return tokens, offsets
+}
+
+// startsValue reports whether the next character of a token starts its
+// value: nothing but a modifier or a "field:" precedes it.
+func startsValue(tok string) bool {
+	return tok == "" || tok == "+" || tok == "-" || strings.HasSuffix(tok, ":")
+}
+
+// rangeEnd returns the index of the bracket closing the range opened at
+// start, or -1.
+func rangeEnd(runes []rune, start int) int {
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == ']' || runes[i] == '}' {
+			return i
+		}
+	}
+	return -1
+}
+
+// regexpEnd returns the index of the slash closing the regular expression
+// opened at start, or -1 when there is none or it does not end the token
+// (route:/api/v1/login is a path, not a regular expression).
+func regexpEnd(runes []rune, start int) int {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '/':
+			if i == start+1 {
+				return -1
+			}
+			if i+1 == len(runes) || runes[i+1] == ' ' || runes[i+1] == '\t' || runes[i+1] == ')' {
+				return i
+			}
+			return -1
+		}
+	}
+	return -1
 }
 
 type queryParser struct {
-	tokens []string
+	tokens  []string
+	offsets []int
+	// length is the length of the query, in characters.
+	length int
 	pos    int
+	// now resolves relative times (timestamp:>now-15m, since:1h).
+	now time.Time
+}
+
+// errorAt reports err at the token i, or at the end of the query when i is
+// past the last token. Errors already positioned are returned as they are.
+func (p *queryParser) errorAt(i int, err error) error {
+	var syntaxErr *QuerySyntaxError
+	if errors.As(err, &syntaxErr) {
+		return err
+	}
+	pos := p.length
+	if i < len(p.offsets) {
+		pos = p.offsets[i]
+	}
+	return &QuerySyntaxError{Pos: pos, Err: err}
 }
 
 func (p *queryParser) peek() (string, bool) {
@@ -232,6 +360,7 @@ func (p *queryParser) peekAt(offset int) (string, bool) {
 // parseValueGroup parses field:(a OR b OR "c d") — an "in" list expanding to
 // a disjunction of field:value terms. A leading - excludes the whole list.
 func (p *queryParser) parseValueGroup(fieldTok string) (query.Query, error) {
+	start := p.pos
 	p.pos += 2 // consume "field:" and "("
 
 	field := fieldTok
@@ -248,7 +377,7 @@ func (p *queryParser) parseValueGroup(fieldTok string) (query.Query, error) {
 	for {
 		tok, ok := p.peek()
 		if !ok {
-			return nil, fmt.Errorf("invalid query: missing closing parenthesis in %svalue list", fieldTok)
+			return nil, p.errorAt(start, fmt.Errorf("invalid query: missing closing parenthesis in %svalue list", fieldTok))
 		}
 		if tok == ")" {
 			p.pos++
@@ -256,21 +385,27 @@ func (p *queryParser) parseValueGroup(fieldTok string) (query.Query, error) {
 		}
 		if tok == "OR" {
 			if expectValue {
-				return nil, fmt.Errorf("invalid query: unexpected OR in %s value list", field)
+				return nil, p.errorAt(p.pos, fmt.Errorf("invalid query: unexpected OR in %s value list", field))
 			}
 			p.pos++
 			expectValue = true
 			continue
 		}
+		if tok == "AND" || tok == "NOT" {
+			return nil, p.errorAt(p.pos, fmt.Errorf("invalid query: %s in the %s value list, which only supports OR", tok, strings.TrimSuffix(field, ":")))
+		}
 		if !expectValue {
-			return nil, fmt.Errorf("invalid query: expected OR between %s values", field)
+			return nil, p.errorAt(p.pos, fmt.Errorf("invalid query: expected OR between %s values", field))
 		}
 		if tok == "(" {
-			return nil, fmt.Errorf("invalid query: nested parentheses in %s value list", field)
+			return nil, p.errorAt(p.pos, fmt.Errorf("invalid query: nested parentheses in %s value list", field))
+		}
+		item, ok, err := p.buildTerm(field + tok)
+		if err == nil && !ok {
+			item, err = buildGroup(field + tok)
 		}
-		item, err := buildGroup(field + tok)
 		if err != nil {
-			return nil, err
+			return nil, p.errorAt(p.pos, err)
 		}
 		if item != nil {
 			items = append(items, item)
@@ -279,7 +414,7 @@ func (p *queryParser) parseValueGroup(fieldTok string) (query.Query, error) {
 		p.pos++
 	}
 	if len(items) == 0 || expectValue {
-		return nil, fmt.Errorf("invalid query: empty or dangling %s value list", field)
+		return nil, p.errorAt(start, fmt.Errorf("invalid query: empty or dangling %s value list", field))
 	}
 
 	var group query.Query
@@ -322,6 +457,7 @@ func (p *queryParser) parseExpr() (query.Query, error) {
 func (p *queryParser) parseAnd() (query.Query, error) {
 	units := make([]query.Query, 0, 2)
 	words := make([]string, 0, 4)
+	wordsStart := 0
 
 	flushWords := func() error {
 		if len(words) == 0 {
@@ -329,7 +465,7 @@ func (p *queryParser) parseAnd() (query.Query, error) {
 		}
 		built, err := buildGroup(strings.Join(words, " "))
 		if err != nil {
-			return err
+			return p.errorAt(wordsStart, err)
 		}
 		if built != nil {
 			units = append(units, built)
@@ -343,39 +479,32 @@ func (p *queryParser) parseAnd() (query.Query, error) {
 		if !ok || tok == "OR" || tok == ")" {
 			break
 		}
-		if tok == "(" {
-			if err := flushWords(); err != nil {
-				return nil, err
+		if tok == "AND" {
+			next, ok := p.peekAt(1)
+			if len(units) == 0 && len(words) == 0 || !ok || next == "OR" || next == "AND" || next == ")" {
+				return nil, p.errorAt(p.pos, fmt.Errorf("invalid query: AND must be between two terms"))
 			}
 			p.pos++
-			sub, err := p.parseExpr()
-			if err != nil {
-				return nil, err
-			}
-			if closing, ok := p.peek(); !ok || closing != ")" {
-				return nil, fmt.Errorf("invalid query: missing closing parenthesis")
+			continue
+		}
+		unit, ok, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if len(words) == 0 {
+				wordsStart = p.pos
 			}
+			words = append(words, tok)
 			p.pos++
-			if sub != nil {
-				units = append(units, sub)
-			}
 			continue
 		}
-		if strings.HasSuffix(tok, ":") && len(tok) > 1 {
-			if next, ok := p.peekAt(1); ok && next == "(" {
-				if err := flushWords(); err != nil {
-					return nil, err
-				}
-				group, err := p.parseValueGroup(tok)
-				if err != nil {
-					return nil, err
-				}
-				units = append(units, group)
-				continue
-			}
+		if err := flushWords(); err != nil {
+			return nil, err
+		}
+		if unit != nil {
+			units = append(units, unit)
 		}
-		words = append(words, tok)
-		p.pos++
 	}
 	if err := flushWords(); err != nil {
 		return nil, err
@@ -391,6 +520,109 @@ func (p *queryParser) parseAnd() (query.Query, error) {
 	}
 }
 
+// parseUnary parses a NOT, a group, a value list or a term bleve's query
+// string syntax cannot express. ok is false, with nothing consumed, for the
+// other words, which parseAnd hands to buildGroup together.
+func (p *queryParser) parseUnary() (q query.Query, ok bool, err error) {
+	tok, _ := p.peek()
+	switch {
+	case tok == "NOT":
+		return p.parseNot()
+	case tok == "(":
+		q, err = p.parseParens()
+		return q, true, err
+	case (tok == "-" || tok == "+") && p.adjacentParen():
+		p.pos++
+		q, err = p.parseParens()
+		if err != nil || q == nil || tok == "+" {
+			return q, true, err
+		}
+		return negate(q), true, nil
+	}
+	if strings.HasSuffix(tok, ":") && len(tok) > 1 {
+		if next, ok := p.peekAt(1); ok && next == "(" {
+			q, err = p.parseValueGroup(tok)
+			return q, true, err
+		}
+	}
+	q, ok, err = p.buildTerm(tok)
+	if err != nil {
+		return nil, false, p.errorAt(p.pos, err)
+	}
+	if ok {
+		p.pos++
+	}
+	return q, ok, nil
+}
+
+// parseNot parses NOT followed by a term, a group or another NOT.
+func (p *queryParser) parseNot() (query.Query, bool, error) {
+	start := p.pos
+	p.pos++
+	next, ok := p.peek()
+	if !ok || next == "OR" || next == "AND" || next == ")" {
+		return nil, false, p.errorAt(start, fmt.Errorf("invalid query: NOT must be followed by a term or a group"))
+	}
+	operand, ok, err := p.parseUnary()
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		if operand, err = buildGroup(next); err != nil {
+			return nil, false, p.errorAt(p.pos, err)
+		}
+		p.pos++
+	}
+	if operand == nil {
+		// Only stop words: there is nothing to exclude.
+		return nil, true, nil
+	}
+	return negate(operand), true, nil
+}
+
+// parseParens parses a parenthesized expression.
+func (p *queryParser) parseParens() (query.Query, error) {
+	open := p.pos
+	p.pos++
+	sub, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if closing, ok := p.peek(); !ok || closing != ")" {
+		return nil, p.errorAt(open, fmt.Errorf("invalid query: missing closing parenthesis"))
+	}
+	p.pos++
+	return sub, nil
+}
+
+// adjacentParen reports whether the current token, a modifier, is directly
+// followed by "(" as in -(a OR b).
+func (p *queryParser) adjacentParen() bool {
+	next, ok := p.peekAt(1)
+	return ok && next == "(" && p.offsets[p.pos+1] == p.offsets[p.pos]+1
+}
+
+func negate(q query.Query) query.Query {
+	return query.NewBooleanQuery(nil, nil, []query.Query{q})
+}
+
+// buildTerm builds the terms bleve's query string syntax cannot express:
+// regular expressions, range terms and exact or case-sensitive terms. ok is
+// false for the other tokens, left to buildGroup.
+func (p *queryParser) buildTerm(token string) (q query.Query, ok bool, err error) {
+	switch {
+	case isRegexpToken(token):
+		q, err = buildRegexp(token)
+	case isRangeToken(token):
+		q, err = buildRange(token, p.now)
+	case isExactToken(token):
+		q, err = buildExact(token)
+	default:
+		return nil, false, nil
+	}
+	return q, err == nil, err
+}
+
 // buildGroup builds one AND group: wildcard tokens plus the query string
 // parser output with all terms required.
 func buildGroup(group string) (query.Query, error) {
@@ -414,6 +646,144 @@ func buildGroup(group string) (query.Query, error) {
 	}
 }
 
+// splitTerm splits a [+|-][field:]value token into its parts.
+func splitTerm(token string) (modifier, field, value string) {
+	value = token
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		modifier = value[:1]
+		value = value[1:]
+	}
+	if colon := strings.Index(value, ":"); colon >= 0 && !strings.HasPrefix(value, "/") {
+		field = value[:colon]
+		value = value[colon+1:]
+	}
+	return modifier, field, value
+}
+
+// isRegexpToken reports whether the token is a [+|-][field:]/pattern/ term,
+// as kept whole by tokenizeQuery.
+func isRegexpToken(token string) bool {
+	_, _, value := splitTerm(token)
+	return len(value) > 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/")
+}
+
+// buildRegexp converts a /pattern/ term to a regexp query. Like wildcards,
+// patterns match whole indexed terms (lowercased words), case-insensitively,
+// so ^ and $ are implied. Terms hold no spaces, so patterns with spaces are
+// matched against the exact sub-field instead, which holds the whole value
+// (of the message, for bare patterns): anywhere in it, unless anchored with
+// ^ or $.
+func buildRegexp(token string) (query.Query, error) {
+	modifier, field, value := splitTerm(token)
+	pattern := value[1 : len(value)-1]
+
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("invalid query: empty regular expression in %s", token)
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, fmt.Errorf("invalid query: invalid regular expression %s: %w", value, err)
+	}
+	var rq *query.RegexpQuery
+	if strings.ContainsFunc(pattern, unicode.IsSpace) {
+		if field == "" {
+			field = processors.FieldMessage
+		}
+		rq = query.NewRegexpQuery(valuePattern(pattern))
+		rq.SetField(processors.ExactField(field))
+	} else {
+		rq = query.NewRegexpQuery("(?i)" + pattern)
+		if field != "" {
+			rq.SetField(field)
+		}
+	}
+
+	if modifier == "-" {
+		return query.NewBooleanQuery(nil, nil, []query.Query{rq}), nil
+	}
+	return rq, nil
+}
+
+// valuePattern turns a pattern into one matching whole values containing a
+// match, case-insensitively; ^ and $ anchor it to the start and the end.
+func valuePattern(pattern string) string {
+	prefix, suffix := "(?is).*", ".*"
+	if strings.HasPrefix(pattern, "^") {
+		prefix, pattern = "(?is)", pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "$") && !strings.HasSuffix(pattern, `\$`) {
+		suffix, pattern = "", pattern[:len(pattern)-1]
+	}
+	return prefix + "(?:" + pattern + ")" + suffix
+}
+
+// isExactToken reports whether the token is a field:=value (exact) or
+// field:~value (case-sensitive) term.
+func isExactToken(token string) bool {
+	_, field, value := splitTerm(token)
+	return field != "" && len(value) > 1 && (value[0] == '=' || value[0] == '~')
+}
+
+// buildExact converts field:=value and field:~value terms to queries on the
+// exact sub-field, which holds string values whole and as logged.
+// field:=value matches the whole value, with * and ? wildcards unless quoted;
+// numbers also match numeric values. field:~value matches value anywhere in
+// the field, case-sensitively and not inside longer words.
+func buildExact(token string) (query.Query, error) {
+	modifier, field, value := splitTerm(token)
+	op, value := value[0], value[1:]
+	quoted := len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)
+	if quoted {
+		value = value[1 : len(value)-1]
+	}
+	if value == "" {
+		return nil, fmt.Errorf("invalid query: empty value in %s", token)
+	}
+
+	exact := processors.ExactField(field)
+	var built query.Query
+	switch {
+	case op == '~':
+		rq := query.NewRegexpQuery(caseSensitivePattern(value))
+		rq.SetField(exact)
+		built = rq
+	case !quoted && strings.ContainsAny(value, "*?"):
+		wq := query.NewWildcardQuery(value)
+		wq.SetField(exact)
+		built = wq
+	default:
+		tq := query.NewTermQuery(value)
+		tq.SetField(exact)
+		built = tq
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			inclusive := true
+			nq := query.NewNumericRangeInclusiveQuery(&n, &n, &inclusive, &inclusive)
+			nq.SetField(field)
+			built = query.NewDisjunctionQuery([]query.Query{tq, nq})
+		}
+	}
+	if modifier == "-" {
+		return query.NewBooleanQuery(nil, nil, []query.Query{built}), nil
+	}
+	return built, nil
+}
+
+// caseSensitivePattern matches values containing s, not preceded or followed
+// by another word character when s starts or ends with one.
+func caseSensitivePattern(s string) string {
+	pattern := regexp.QuoteMeta(s)
+	if isWordByte(s[0]) {
+		pattern = `\b` + pattern
+	}
+	if isWordByte(s[len(s)-1]) {
+		pattern += `\b`
+	}
+	return `(?s).*` + pattern + `.*`
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
 // extractWildcards pulls tokens containing * or ? out of the query string
 // (bleve's query string syntax has no wildcard support) and converts them to
 // wildcard queries matching inside indexed terms, e.g. msg:*onnect* or
@@ -2,6 +2,7 @@ package entryreader_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -36,9 +37,10 @@ func TestReader_Search(t *testing.T) {
 		m.Set("msg", fmt.Sprintf("message number%d", i))
 		m.Set("field1", "value1")
 		m.Set("route", routes[i])
-		entry := domain.Entry(*m)
+		entry := domain.Entry{OrderedMap: *m, Raw: fmt.Sprintf("raw line %d", i)}
 		require.NoError(t, indexer.Process(ctx, &entry))
 	}
+	require.NoError(t, indexer.Flush())
 
 	reader := entryreader.NewReader(index)
 
@@ -52,6 +54,8 @@ func TestReader_Search(t *testing.T) {
 		fields := got.Entries[0].Fields.Keys()
 		assert.Contains(t, fields, "field1")
 		assert.Contains(t, fields, "route")
+		assert.NotContains(t, fields, processors.FieldRaw)
+		assert.Equal(t, "raw line 2", got.Entries[0].Raw)
 	})
 
 	t.Run("should return entries since the given time (inclusive) when until is not given", func(t *testing.T) {
@@ -70,6 +74,12 @@ func TestReader_Search(t *testing.T) {
 		assert.Equal(t, "message number2", got.Entries[2].Message)
 	})
 
+	t.Run("should not search the raw line", func(t *testing.T) {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: "raw"})
+		require.NoError(t, err)
+		assert.Empty(t, got.Entries)
+	})
+
 	t.Run("should filter entries by query", func(t *testing.T) {
 		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: "number1"})
 		require.NoError(t, err)
@@ -233,6 +243,52 @@ func TestReader_Search(t *testing.T) {
 		assert.Len(t, got.Entries, 2)
 	})
 
+	t.Run("should support AND, NOT and negated groups", func(t *testing.T) {
+		for q, want := range map[string][]string{
+			// AND is optional between terms.
+			"level:info AND route:/api/v1/login": {"message number2"},
+			// NOT binds tighter than AND, and AND than OR.
+			"NOT level:error":                               {"message number2", "message number0"},
+			"NOT level:error route:/api/v1/login":           {"message number2"},
+			"level:error OR level:info AND message:number0": {"message number1", "message number0"},
+			"NOT level:info AND route:/api/v1/login OR message:number0": {
+				"message number1", "message number0",
+			},
+			"NOT (level:error OR message:number0)": {"message number2"},
+			"-(level:error OR message:number0)":    {"message number2"},
+			"field1:value1 -(level:info message:number2)": {
+				"message number1", "message number0",
+			},
+			"NOT NOT level:error":                {"message number1"},
+			"NOT level:(info OR error)":          {},
+			"level:info AND NOT message:number0": {"message number2"},
+		} {
+			got, err := reader.Search(ctx, entryreader.SearchRequest{Query: q})
+			require.NoError(t, err, q)
+			msgs := make([]string, 0, len(got.Entries))
+			for _, e := range got.Entries {
+				msgs = append(msgs, e.Message)
+			}
+			assert.Equal(t, want, msgs, q)
+		}
+	})
+
+	t.Run("should fail on misplaced AND and NOT", func(t *testing.T) {
+		for q, want := range map[string]string{
+			"AND level:error":               "invalid query: AND must be between two terms",
+			"level:error AND":               "invalid query: AND must be between two terms",
+			"level:error AND OR level:info": "invalid query: AND must be between two terms",
+			"(level:error AND)":             "invalid query: AND must be between two terms",
+			"level:error NOT":               "invalid query: NOT must be followed by a term or a group",
+			"NOT OR level:error":            "invalid query: NOT must be followed by a term or a group",
+			"level:(error AND info)":        "invalid query: AND in the level value list, which only supports OR",
+			"level:(NOT error)":             "invalid query: NOT in the level value list, which only supports OR",
+		} {
+			_, err := reader.Search(ctx, entryreader.SearchRequest{Query: q})
+			assert.EqualError(t, err, want, q)
+		}
+	})
+
 	t.Run("should fail on malformed value lists", func(t *testing.T) {
 		for _, q := range []string{
 			"level:(error fatal)",
@@ -270,13 +326,122 @@ func TestReader_Search(t *testing.T) {
 		assert.Len(t, got.Entries, 3)
 	})
 
+	t.Run("should match terms with regular expressions", func(t *testing.T) {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: `message:/number[12]/`})
+		require.NoError(t, err)
+		assert.Len(t, got.Entries, 2)
+
+		// Case-insensitive and anchored to whole terms.
+		got, err = reader.Search(ctx, entryreader.SearchRequest{Query: `/NUMBER\d/`})
+		require.NoError(t, err)
+		assert.Len(t, got.Entries, 3)
+		got, err = reader.Search(ctx, entryreader.SearchRequest{Query: `message:/umber\d/`})
+		require.NoError(t, err)
+		assert.Empty(t, got.Entries)
+	})
+
+	t.Run("should keep parentheses and wildcards inside regular expressions", func(t *testing.T) {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: `message:/mes.*/ message:/(number0|number2)/`})
+		require.NoError(t, err)
+		require.Len(t, got.Entries, 2)
+		assert.Equal(t, "message number2", got.Entries[0].Message)
+		assert.Equal(t, "message number0", got.Entries[1].Message)
+	})
+
+	t.Run("should match regular expressions with spaces against whole values", func(t *testing.T) {
+		for q, want := range map[string]int{
+			`message:/sage number[01]/`:   2,
+			`/MESSAGE number\d/`:          3,
+			`message:/^message number2$/`: 1,
+			`message:/^sage number2/`:     0,
+			`-message:/message number0$/`: 2,
+			`message:/number0 message/`:   0,
+		} {
+			got, err := reader.Search(ctx, entryreader.SearchRequest{Query: "field1:value1 " + q})
+			require.NoError(t, err, q)
+			assert.Len(t, got.Entries, want, q)
+		}
+	})
+
+	t.Run("should support negated regular expressions and value lists", func(t *testing.T) {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: `field1:value1 -message:/number[01]/`})
+		require.NoError(t, err)
+		require.Len(t, got.Entries, 1)
+		assert.Equal(t, "message number2", got.Entries[0].Message)
+
+		got, err = reader.Search(ctx, entryreader.SearchRequest{Query: `level:(/err.*/ OR debug)`})
+		require.NoError(t, err)
+		require.Len(t, got.Entries, 1)
+		assert.Equal(t, "message number1", got.Entries[0].Message)
+	})
+
+	t.Run("should keep paths as plain terms", func(t *testing.T) {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: "route:/api/v1/login"})
+		require.NoError(t, err)
+		assert.Len(t, got.Entries, 2)
+	})
+
+	t.Run("should fail on invalid regular expressions", func(t *testing.T) {
+		_, err := reader.Search(ctx, entryreader.SearchRequest{Query: `message:/number(/`})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid regular expression /number(/")
+	})
+
+	t.Run("should filter by time ranges", func(t *testing.T) {
+		for q, want := range map[string]int{
+			"timestamp:[2026-01-01T12:00:01Z TO *]":                     2,
+			"timestamp:{2026-01-01T12:00:01Z TO *]":                     1,
+			`timestamp:["2026-01-01 12:00:00" TO 2026-01-01T12:00:01Z}`: 1,
+			"-timestamp:[2026-01-01T12:00:01Z TO *]":                    1,
+			"timestamp:>=2026-01-01T12:00:01Z level:info":               1,
+			"timestamp:[2026-01-01 TO 2026-01-01]":                      3,
+			"timestamp:>2026-01-01":                                     0,
+			"timestamp:<=2025-12-31":                                    0,
+			"level:(info OR error) timestamp:<2026-01-01T12:00:02Z":     2,
+		} {
+			got, err := reader.Search(ctx, entryreader.SearchRequest{Query: q})
+			require.NoError(t, err, q)
+			assert.Len(t, got.Entries, want, q)
+		}
+	})
+
+	t.Run("should filter by times relative to now", func(t *testing.T) {
+		for q, want := range map[string]int{
+			"timestamp:>now-15m":           0,
+			"timestamp:<now":               3,
+			"timestamp:[now-1w-1d TO now]": 0,
+			"since:1h":                     0,
+			"since:2026-01-01T12:00:02Z":   1,
+			"-since:30d":                   3,
+		} {
+			got, err := reader.Search(ctx, entryreader.SearchRequest{Query: q})
+			require.NoError(t, err, q)
+			assert.Len(t, got.Entries, want, q)
+		}
+	})
+
+	t.Run("should fail on invalid time ranges", func(t *testing.T) {
+		for _, q := range []string{
+			"timestamp:>now-15x",
+			"timestamp:[yesterday TO now]",
+			"timestamp:[* TO *]",
+			"timestamp:[now]",
+			"since:soon",
+		} {
+			_, err := reader.Search(ctx, entryreader.SearchRequest{Query: q})
+			assert.Error(t, err, "query %q should fail", q)
+		}
+	})
+
 	t.Run("should list searchable fields without internal ones", func(t *testing.T) {
-		fields, err := reader.Fields(ctx)
+		got, err := reader.Fields(ctx)
 		require.NoError(t, err)
+		fields := fieldNames(got)
 		assert.Contains(t, fields, "field1")
 		assert.Contains(t, fields, "level")
 		assert.Contains(t, fields, "message")
 		assert.NotContains(t, fields, "_all")
+		assert.NotContains(t, fields, processors.FieldRaw)
 		assert.IsIncreasing(t, fields)
 	})
 
@@ -307,3 +472,212 @@ func TestReader_Search(t *testing.T) {
 		assert.Empty(t, values)
 	})
 }
+
+func TestReader_Search_exact(t *testing.T) {
+	ctx := context.Background()
+
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+
+	indexer := processors.NewIndexer(index)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, fields := range [][2]string{
+		{"AbC-123", "Error while connecting"},
+		{"abc-123", "error: connection refused"},
+		{"AbC-1234", "Errors everywhere"},
+	} {
+		m := orderedmap.New()
+		m.Set("ts", base.Add(time.Duration(i)*time.Second).Format(time.RFC3339))
+		m.Set("request_id", fields[0])
+		m.Set("msg", fields[1])
+		entry := domain.Entry{OrderedMap: *m}
+		require.NoError(t, indexer.Process(ctx, &entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	reader := entryreader.NewReader(index)
+	messages := func(t *testing.T, q string) []string {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: q, Ascending: true})
+		require.NoError(t, err)
+		msgs := make([]string, 0, len(got.Entries))
+		for _, e := range got.Entries {
+			msgs = append(msgs, e.Message)
+		}
+		return msgs
+	}
+
+	t.Run("should match whole values exactly", func(t *testing.T) {
+		assert.Len(t, messages(t, "request_id:abc-123"), 2)
+		assert.Equal(t, []string{"Error while connecting"}, messages(t, "request_id:=AbC-123"))
+		assert.Equal(t, []string{"error: connection refused"}, messages(t, "-request_id:=AbC*"))
+		assert.Equal(t, []string{"Errors everywhere"}, messages(t, `message:="Errors everywhere"`))
+		assert.Empty(t, messages(t, "message:=Errors"))
+	})
+
+	t.Run("should match words case-sensitively", func(t *testing.T) {
+		assert.Equal(t, []string{"Error while connecting"}, messages(t, "message:~Error"))
+		assert.Equal(t, []string{"error: connection refused"}, messages(t, `message:~"error: connection"`))
+		assert.Empty(t, messages(t, `message:~"error: conn"`))
+	})
+
+	t.Run("should not list the exact sub-fields", func(t *testing.T) {
+		got, err := reader.Fields(ctx)
+		require.NoError(t, err)
+		fields := fieldNames(got)
+		assert.Contains(t, fields, "request_id")
+		assert.NotContains(t, fields, processors.ExactField("request_id"))
+	})
+
+	t.Run("should not return the exact sub-fields", func(t *testing.T) {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: "request_id:=AbC-123"})
+		require.NoError(t, err)
+		require.Len(t, got.Entries, 1)
+		assert.Equal(t, []string{"request_id"}, got.Entries[0].Fields.Keys())
+	})
+}
+
+func TestReader_Search_units(t *testing.T) {
+	ctx := context.Background()
+
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+
+	indexer := processors.NewIndexer(index)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, line := range []string{
+		`{"msg":"fast","latency":"152ms","size":"1.2MB","status":"503"}`,
+		`{"msg":"slow","latency":"1.5s","size":"512 KiB","status":200}`,
+		`{"msg":"slowest","latency":"2m","size":"3GB","status":"404"}`,
+		`{"msg":"unitless","latency":120,"size":2000000}`,
+	} {
+		entry := domain.NewEntry()
+		require.NoError(t, json.Unmarshal([]byte(line), &entry.OrderedMap))
+		entry.Set("ts", base.Add(time.Duration(i)*time.Second).Format(time.RFC3339))
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	reader := entryreader.NewReader(index)
+	messages := func(t *testing.T, q string) []string {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: q, Ascending: true})
+		require.NoError(t, err, q)
+		msgs := make([]string, 0, len(got.Entries))
+		for _, e := range got.Entries {
+			msgs = append(msgs, e.Message)
+		}
+		return msgs
+	}
+
+	t.Run("should compare numeric strings as numbers", func(t *testing.T) {
+		assert.Equal(t, []string{"fast"}, messages(t, "status:>=500"))
+		assert.Equal(t, []string{"slow", "slowest"}, messages(t, "status:<500"))
+		assert.Equal(t, []string{"fast", "slowest"}, messages(t, "status:[400 TO 599]"))
+	})
+
+	t.Run("should compare durations whatever their unit", func(t *testing.T) {
+		assert.Equal(t, []string{"fast", "slow", "slowest"}, messages(t, "latency:>100ms"))
+		assert.Equal(t, []string{"slow", "slowest"}, messages(t, "latency:>=1.5s"))
+		assert.Equal(t, []string{"fast", "slow"}, messages(t, "latency:[100ms TO 1m]"))
+		assert.Equal(t, []string{"unitless"}, messages(t, "latency:>100"))
+	})
+
+	t.Run("should compare byte sizes whatever their unit", func(t *testing.T) {
+		assert.Equal(t, []string{"fast", "slowest"}, messages(t, "size:>1MB"))
+		assert.Equal(t, []string{"slow"}, messages(t, "size:<1MiB"))
+		assert.Equal(t, []string{"fast"}, messages(t, "size:{512KiB TO 1GB}"))
+		assert.Equal(t, []string{"fast", "slow", "slowest"}, messages(t, "-size:>1000000"))
+	})
+
+	t.Run("should fail on ranges mixing units", func(t *testing.T) {
+		_, err := reader.Search(ctx, entryreader.SearchRequest{Query: "size:[1MB TO 1s]"})
+		assert.EqualError(t, err, "invalid query: size:[1MB TO 1s]: the bounds have different kinds of units (bytes and duration)")
+	})
+
+	t.Run("should not list the quantity sub-fields", func(t *testing.T) {
+		got, err := reader.Fields(ctx)
+		require.NoError(t, err)
+		fields := fieldNames(got)
+		assert.Contains(t, fields, "latency")
+		for _, f := range fields {
+			assert.False(t, processors.IsSubField(f), f)
+		}
+	})
+}
+
+func TestReader_Search_typeConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+
+	indexer := processors.NewIndexer(index)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, line := range []string{
+		`{"msg":"number","status":503,"ok":true,"created":"2025-06-01T00:00:00Z"}`,
+		`{"msg":"string","status":"503","ok":"yes","created":"last week"}`,
+		`{"msg":"other","status":200,"ok":false}`,
+	} {
+		entry := domain.NewEntry()
+		require.NoError(t, json.Unmarshal([]byte(line), &entry.OrderedMap))
+		entry.Set("ts", base.Add(time.Duration(i)*time.Second).Format(time.RFC3339))
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	reader := entryreader.NewReader(index)
+	messages := func(t *testing.T, q string) []string {
+		got, err := reader.Search(ctx, entryreader.SearchRequest{Query: q, Ascending: true})
+		require.NoError(t, err, q)
+		msgs := make([]string, 0, len(got.Entries))
+		for _, e := range got.Entries {
+			msgs = append(msgs, e.Message)
+		}
+		return msgs
+	}
+
+	t.Run("should list the types of the fields", func(t *testing.T) {
+		got, err := reader.Fields(ctx)
+		require.NoError(t, err)
+		types := make(map[string][]string, len(got))
+		conflicts := make([]string, 0)
+		for _, f := range got {
+			types[f.Name] = f.Types
+			if f.Conflict() {
+				conflicts = append(conflicts, f.Name)
+			}
+		}
+		assert.Equal(t, []string{"number", "string"}, types["status"])
+		assert.Equal(t, []string{"boolean", "string"}, types["ok"])
+		assert.Equal(t, []string{"date", "string"}, types["created"])
+		assert.Equal(t, []string{"string"}, types["message"])
+		assert.Empty(t, types["timestamp"])
+		assert.Equal(t, []string{"created", "ok", "status"}, conflicts)
+	})
+
+	t.Run("should match conflicting fields under both representations", func(t *testing.T) {
+		assert.Equal(t, []string{"number", "string"}, messages(t, "status:503"))
+		assert.Equal(t, []string{"number", "string"}, messages(t, "status:>500"))
+		assert.Equal(t, []string{"number", "string"}, messages(t, "status:50*"))
+		assert.Equal(t, []string{"number", "string"}, messages(t, "status:/5\\d+/"))
+		assert.Equal(t, []string{"number"}, messages(t, "ok:true"))
+		assert.Equal(t, []string{"number"}, messages(t, "created:2025*"))
+		assert.Equal(t, []string{"number"}, messages(t, "created:>=2025-01-01"))
+	})
+}
+
+func fieldNames(fields []entryreader.Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
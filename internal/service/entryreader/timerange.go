@@ -0,0 +1,276 @@
+package entryreader
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/jamillosantos/lovr/internal/service/processors"
+	"github.com/jamillosantos/lovr/internal/timestamp"
+	"github.com/jamillosantos/lovr/internal/units"
+)
+
+// Range terms cover what bleve's query string syntax cannot express:
+//
+//	timestamp:>now-15m                    comparisons with now, shifted by offsets
+//	timestamp:<=2024-05-01                comparisons with unquoted times
+//	timestamp:[2024-05-01 TO 2024-05-02]  inclusive [ ] or exclusive { } ranges
+//	status:[500 TO *]                     numeric ranges, * for an open end
+//	latency:>100ms, size:[1MB TO 1GB]     durations and byte sizes
+//	since:1h                              shorthand for timestamp:>=now-1h
+//
+// Date-only upper bounds include the whole day, as in Elasticsearch. Numbers
+// match numeric values and numeric strings; durations and byte sizes match
+// strings logged with a unit of their kind (see processors.QuantityField).
+
+// sinceField is the pseudo field of since:1h terms.
+const sinceField = "since"
+
+var (
+	nowPattern     = regexp.MustCompile(`^now((?:[+-]\d+(?:ms|s|m|h|d|w))*)$`)
+	offsetPattern  = regexp.MustCompile(`([+-]?)(\d+)(ms|s|m|h|d|w)`)
+	durationRegexp = regexp.MustCompile(`^(?:\d+(?:ms|s|m|h|d|w))+$`)
+	rangeSeparator = regexp.MustCompile(`\s+TO\s+`)
+)
+
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+// isRangeToken reports whether the token is a since:, time comparison or
+// [from TO to] range term.
+func isRangeToken(token string) bool {
+	_, field, value := splitTerm(token)
+	switch {
+	case field == "":
+		return false
+	case field == sinceField, isBracketed(value):
+		return true
+	}
+	if op, rest := cutComparison(value); op != "" {
+		_, quantity := units.Parse(rest)
+		return quantity || strings.HasPrefix(rest, "now") || isUnquotedTime(rest)
+	}
+	return false
+}
+
+// buildRange converts a range term to a date or numeric range query, with
+// relative times resolved against now.
+func buildRange(token string, now time.Time) (query.Query, error) {
+	modifier, field, value := splitTerm(token)
+
+	var built query.Query
+	var err error
+	switch {
+	case field == sinceField:
+		built, err = sinceQuery(value, now)
+	case isBracketed(value):
+		built, err = bracketQuery(field, value, now)
+	default:
+		built, err = comparisonQuery(field, value, now)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %s: %w", token, err)
+	}
+	if modifier == "-" {
+		return query.NewBooleanQuery(nil, nil, []query.Query{built}), nil
+	}
+	return built, nil
+}
+
+func sinceQuery(value string, now time.Time) (query.Query, error) {
+	var start time.Time
+	if durationRegexp.MatchString(value) {
+		d, err := parseOffsets(value)
+		if err != nil {
+			return nil, err
+		}
+		start = now.Add(-d)
+	} else {
+		var err error
+		if start, _, err = parseTimeBound(value, now); err != nil {
+			return nil, fmt.Errorf("expected a duration (15m, 1h, 7d) or a time")
+		}
+	}
+	inclusive := true
+	dr := query.NewDateRangeInclusiveQuery(start, time.Time{}, &inclusive, &inclusive)
+	dr.SetField(processors.FieldTimestamp)
+	return dr, nil
+}
+
+func comparisonQuery(field, value string, now time.Time) (query.Query, error) {
+	op, rest := cutComparison(value)
+	inclusive := op == ">=" || op == "<="
+	if q, ok := units.Parse(rest); ok {
+		if op[0] == '>' {
+			return quantityRange(field, q.Kind, &q.Value, nil, &inclusive, nil), nil
+		}
+		return quantityRange(field, q.Kind, nil, &q.Value, nil, &inclusive), nil
+	}
+	t, dayOnly, err := parseTimeBound(rest, now)
+	if err != nil {
+		return nil, err
+	}
+	var start, end time.Time
+	switch op {
+	case ">", ">=":
+		if dayOnly && op == ">" {
+			t, inclusive = t.AddDate(0, 0, 1), true
+		}
+		start = t
+	default:
+		if dayOnly && op == "<=" {
+			t, inclusive = t.AddDate(0, 0, 1), false
+		}
+		end = t
+	}
+	dr := query.NewDateRangeInclusiveQuery(start, end, &inclusive, &inclusive)
+	dr.SetField(field)
+	return dr, nil
+}
+
+func bracketQuery(field, value string, now time.Time) (query.Query, error) {
+	minInclusive := value[0] == '['
+	maxInclusive := value[len(value)-1] == ']'
+	bounds := rangeSeparator.Split(strings.TrimSpace(value[1:len(value)-1]), -1)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("expected [from TO to]")
+	}
+	from, to := strings.Trim(bounds[0], `"`), strings.Trim(bounds[1], `"`)
+	if from == "*" && to == "*" {
+		return nil, fmt.Errorf("the range has no bounds")
+	}
+
+	fromQ, fromOK := units.Parse(from)
+	toQ, toOK := units.Parse(to)
+	if (from == "*" || fromOK) && (to == "*" || toOK) {
+		var min, max *float64
+		kind := fromQ.Kind
+		if from != "*" {
+			min = &fromQ.Value
+		}
+		if to != "*" {
+			max = &toQ.Value
+			if from != "*" && toQ.Kind != kind {
+				return nil, fmt.Errorf("the bounds have different kinds of units (%s and %s)", fromQ.Kind, toQ.Kind)
+			}
+			kind = toQ.Kind
+		}
+		return quantityRange(field, kind, min, max, &minInclusive, &maxInclusive), nil
+	}
+
+	var start, end time.Time
+	if from != "*" {
+		t, dayOnly, err := parseTimeBound(from, now)
+		if err != nil {
+			return nil, err
+		}
+		if dayOnly && !minInclusive {
+			t, minInclusive = t.AddDate(0, 0, 1), true
+		}
+		start = t
+	}
+	if to != "*" {
+		t, dayOnly, err := parseTimeBound(to, now)
+		if err != nil {
+			return nil, err
+		}
+		if dayOnly && maxInclusive {
+			t, maxInclusive = t.AddDate(0, 0, 1), false
+		}
+		end = t
+	}
+	dr := query.NewDateRangeInclusiveQuery(start, end, &minInclusive, &maxInclusive)
+	dr.SetField(field)
+	return dr, nil
+}
+
+// quantityRange compares the quantities of the field: numbers with its
+// numeric values and numeric strings, durations and byte sizes with its
+// strings holding one of their kind.
+func quantityRange(field string, kind units.Kind, min, max *float64, minInclusive, maxInclusive *bool) query.Query {
+	sub := query.NewNumericRangeInclusiveQuery(min, max, minInclusive, maxInclusive)
+	sub.SetField(processors.QuantityField(field, kind))
+	if kind != units.Number {
+		return sub
+	}
+	nq := query.NewNumericRangeInclusiveQuery(min, max, minInclusive, maxInclusive)
+	nq.SetField(field)
+	return query.NewDisjunctionQuery([]query.Query{nq, sub})
+}
+
+// parseTimeBound parses now[+-offset...] or a time in any layout known to
+// the timestamp package. dayOnly reports a date without time of day.
+func parseTimeBound(s string, now time.Time) (t time.Time, dayOnly bool, err error) {
+	if m := nowPattern.FindStringSubmatch(s); m != nil {
+		d, err := parseOffsets(m[1])
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		return now.Add(d), false, nil
+	}
+	if strings.HasPrefix(s, "now") {
+		return time.Time{}, false, fmt.Errorf("invalid relative time %q (e.g. now-15m, now-1d+2h)", s)
+	}
+	t, ok := timestamp.Parse(s)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("invalid time %q", s)
+	}
+	_, err = time.Parse(time.DateOnly, s)
+	return t, err == nil, nil
+}
+
+// parseOffsets sums offsets such as -1d+2h or 15m.
+func parseOffsets(s string) (time.Duration, error) {
+	var total time.Duration
+	for _, m := range offsetPattern.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.ParseInt(m[2], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset %q", m[0])
+		}
+		d := time.Duration(n) * durationUnits[m[3]]
+		if m[1] == "-" {
+			d = -d
+		}
+		total += d
+	}
+	return total, nil
+}
+
+// cutComparison splits >, >=, < and <= off the value.
+func cutComparison(value string) (op, rest string) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if rest, ok := strings.CutPrefix(value, op); ok {
+			return op, rest
+		}
+	}
+	return "", value
+}
+
+// isUnquotedTime reports whether the value is a time that bleve would not
+// parse: numbers are left to its numeric comparisons and quoted times to
+// its date comparisons.
+func isUnquotedTime(value string) bool {
+	if value == "" || strings.HasPrefix(value, `"`) {
+		return false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return false
+	}
+	_, ok := timestamp.Parse(value)
+	return ok
+}
+
+func isBracketed(value string) bool {
+	return len(value) >= 2 && strings.ContainsRune("[{", rune(value[0])) &&
+		strings.ContainsRune("]}", rune(value[len(value)-1]))
+}
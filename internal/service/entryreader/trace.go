@@ -0,0 +1,144 @@
+package entryreader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/service/processors"
+)
+
+// MaxTraceEntries caps the entries returned for a single trace.
+const MaxTraceEntries = 10000
+
+// ErrTraceNotFound is returned by Trace when no entry carries the trace ID.
+var ErrTraceNotFound = errors.New("trace not found")
+
+// TraceSpan groups the entries logged within a span. Start and End are the
+// timestamps of its first and last entries.
+type TraceSpan struct {
+	SpanID       string
+	ParentSpanID string
+	Start        time.Time
+	End          time.Time
+	Entries      []*domain.LogEntry
+	Children     []*TraceSpan
+}
+
+type TraceResponse struct {
+	TraceID string
+	Start   time.Time
+	End     time.Time
+	// Entries are all the entries of the trace, oldest first.
+	Entries []*domain.LogEntry
+	// Spans are the root spans: those without a parent, or whose parent
+	// logged nothing. Entries without a span ID are grouped in a span with
+	// an empty ID.
+	Spans []*TraceSpan
+	// Truncated reports that the trace has more than MaxTraceEntries entries.
+	Truncated bool
+}
+
+// Trace returns the entries of a trace ordered by time, with the span
+// hierarchy rebuilt from the span and parent span IDs.
+func (r *Reader) Trace(ctx context.Context, traceID string) (TraceResponse, error) {
+	q := bleve.NewTermQuery(traceID)
+	q.SetField(processors.FieldTraceID)
+
+	request := bleve.NewSearchRequestOptions(q, MaxTraceEntries, 0, false)
+	request.SortBy([]string{processors.FieldTimestamp})
+	request.Fields = []string{"*"}
+
+	result, err := r.index.SearchInContext(ctx, request)
+	if err != nil {
+		return TraceResponse{}, fmt.Errorf("error searching the trace: %w", err)
+	}
+	if len(result.Hits) == 0 {
+		return TraceResponse{}, ErrTraceNotFound
+	}
+
+	entries := make([]*domain.LogEntry, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		entries = append(entries, hitToLogEntry(hit))
+	}
+	// The stored timestamp is truncated to seconds: order within a second
+	// by the full precision one.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return TraceResponse{
+		TraceID:   traceID,
+		Start:     entries[0].Timestamp,
+		End:       entries[len(entries)-1].Timestamp,
+		Entries:   entries,
+		Spans:     buildSpanTree(entries),
+		Truncated: result.Total > uint64(len(result.Hits)),
+	}, nil
+}
+
+// buildSpanTree groups entries (oldest first) by span and links the spans to
+// their parents. Spans are ordered by their first entry.
+func buildSpanTree(entries []*domain.LogEntry) []*TraceSpan {
+	spans := make(map[string]*TraceSpan)
+	ordered := make([]*TraceSpan, 0)
+	for _, entry := range entries {
+		span, ok := spans[entry.SpanID]
+		if !ok {
+			span = &TraceSpan{
+				SpanID: entry.SpanID,
+				Start:  entry.Timestamp,
+			}
+			spans[entry.SpanID] = span
+			ordered = append(ordered, span)
+		}
+		if span.ParentSpanID == "" && entry.ParentSpanID != entry.SpanID {
+			span.ParentSpanID = entry.ParentSpanID
+		}
+		span.End = entry.Timestamp
+		span.Entries = append(span.Entries, entry)
+	}
+
+	children := make(map[*TraceSpan][]*TraceSpan)
+	roots := make([]*TraceSpan, 0, 1)
+	for _, span := range ordered {
+		parent, ok := spans[span.ParentSpanID]
+		if span.SpanID == "" || span.ParentSpanID == "" || !ok {
+			roots = append(roots, span)
+			continue
+		}
+		children[parent] = append(children[parent], span)
+	}
+
+	attached := make(map[*TraceSpan]bool, len(ordered))
+	var attach func(span *TraceSpan)
+	attach = func(span *TraceSpan) {
+		attached[span] = true
+		for _, child := range children[span] {
+			if !attached[child] {
+				span.Children = append(span.Children, child)
+				attach(child)
+			}
+		}
+	}
+	for _, root := range roots {
+		attach(root)
+	}
+	// Spans not reachable from a root are parents of each other: break the
+	// cycle at the earliest one.
+	for _, span := range ordered {
+		if !attached[span] {
+			roots = append(roots, span)
+			attach(span)
+		}
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		return roots[i].Start.Before(roots[j].Start)
+	})
+	return roots
+}
@@ -0,0 +1,120 @@
+package entryreader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+	"github.com/jamillosantos/lovr/internal/service/processors"
+)
+
+func TestReader_Trace(t *testing.T) {
+	ctx := context.Background()
+
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+
+	indexer := processors.NewIndexer(index)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	lines := []struct {
+		offset      time.Duration
+		trace, span string
+		parent, msg string
+	}{
+		{offset: 0, trace: "t1", span: "root", msg: "request received"},
+		{offset: 10 * time.Millisecond, trace: "t1", span: "db", parent: "root", msg: "query"},
+		{offset: 5 * time.Millisecond, trace: "t1", span: "auth", parent: "root", msg: "authenticated"},
+		{offset: 20 * time.Millisecond, trace: "t1", span: "root", msg: "request done"},
+		{offset: 15 * time.Millisecond, trace: "t2", span: "other", msg: "another trace"},
+		{offset: 12 * time.Millisecond, trace: "t1", span: "cache", parent: "db", msg: "cache miss"},
+	}
+	for _, l := range lines {
+		entry := domain.NewEntry()
+		entry.Set("ts", base.Add(l.offset).Format(time.RFC3339Nano))
+		entry.Set("msg", l.msg)
+		entry.Set("traceId", l.trace)
+		entry.Set("span_id", l.span)
+		if l.parent != "" {
+			entry.Set("parentSpanId", l.parent)
+		}
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	reader := entryreader.NewReader(index)
+
+	t.Run("should return the entries of the trace oldest first", func(t *testing.T) {
+		got, err := reader.Trace(ctx, "t1")
+		require.NoError(t, err)
+		assert.Equal(t, "t1", got.TraceID)
+		msgs := make([]string, 0, len(got.Entries))
+		for _, e := range got.Entries {
+			msgs = append(msgs, e.Message)
+		}
+		assert.Equal(t, []string{"request received", "authenticated", "query", "cache miss", "request done"}, msgs)
+		assert.Equal(t, base, got.Start)
+		assert.Equal(t, base.Add(20*time.Millisecond), got.End)
+		assert.False(t, got.Truncated)
+	})
+
+	t.Run("should rebuild the span hierarchy", func(t *testing.T) {
+		got, err := reader.Trace(ctx, "t1")
+		require.NoError(t, err)
+		require.Len(t, got.Spans, 1)
+		root := got.Spans[0]
+		assert.Equal(t, "root", root.SpanID)
+		assert.Len(t, root.Entries, 2)
+		assert.Equal(t, base.Add(20*time.Millisecond), root.End)
+		require.Len(t, root.Children, 2)
+		assert.Equal(t, "auth", root.Children[0].SpanID)
+		assert.Equal(t, "db", root.Children[1].SpanID)
+		require.Len(t, root.Children[1].Children, 1)
+		assert.Equal(t, "cache", root.Children[1].Children[0].SpanID)
+		assert.Equal(t, "db", root.Children[1].Children[0].ParentSpanID)
+	})
+
+	t.Run("should return ErrTraceNotFound for unknown traces", func(t *testing.T) {
+		_, err := reader.Trace(ctx, "unknown")
+		assert.ErrorIs(t, err, entryreader.ErrTraceNotFound)
+	})
+}
+
+func TestReader_Trace_cycles(t *testing.T) {
+	ctx := context.Background()
+
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+
+	indexer := processors.NewIndexer(index)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, ids := range [][2]string{{"a", "b"}, {"b", "a"}, {"c", "missing"}} {
+		entry := domain.NewEntry()
+		entry.Set("ts", base.Add(time.Duration(i)*time.Millisecond).Format(time.RFC3339Nano))
+		entry.Set("msg", "span "+ids[0])
+		entry.Set("trace_id", "t")
+		entry.Set("span_id", ids[0])
+		entry.Set("parent_span_id", ids[1])
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	got, err := entryreader.NewReader(index).Trace(ctx, "t")
+	require.NoError(t, err)
+	require.Len(t, got.Spans, 2)
+	assert.Equal(t, "a", got.Spans[0].SpanID)
+	require.Len(t, got.Spans[0].Children, 1)
+	assert.Equal(t, "b", got.Spans[0].Children[0].SpanID)
+	assert.Equal(t, "c", got.Spans[1].SpanID)
+}
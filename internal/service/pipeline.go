@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+// queuedItemsPerWorker bounds the queues between the pipeline stages, and so
+// how far ahead of the slowest stage the reading goes.
+const queuedItemsPerWorker = 64
+
+// pipelineItem is a line read from the source as it travels through the
+// stages. seq restores the input order before the sequential stages.
+type pipelineItem struct {
+	seq uint64
+
+	// unparsed is set, along with line, when the parsing is left to the
+	// workers.
+	unparsed   bool
+	line       []byte
+	lineNumber int
+
+	entries []*domain.Entry
+	final   bool
+}
+
+// pipelineStage is a run of consecutive processors that are either all
+// ConcurrentProcessors (run on the worker pool, in any order) or all not
+// (run one item at a time, in input order).
+type pipelineStage struct {
+	processors []EntryProcessor
+	concurrent bool
+}
+
+// splitStages groups the processors into stages. The first stage is always
+// concurrent, as it parses the lines. The processors following an
+// EntryEmitter are all sequential: emitted entries may replace earlier ones
+// (the Deduper reuses the ID of the entry it collapses), so they must be
+// handled after them.
+func splitStages(entryProcessors []EntryProcessor) []pipelineStage {
+	stages := []pipelineStage{{concurrent: true}}
+	afterEmitter := false
+	for _, ep := range entryProcessors {
+		_, concurrent := ep.(ConcurrentProcessor)
+		concurrent = concurrent && !afterEmitter
+		if _, ok := ep.(EntryEmitter); ok {
+			afterEmitter = true
+		}
+		last := &stages[len(stages)-1]
+		if last.concurrent == concurrent {
+			last.processors = append(last.processors, ep)
+			continue
+		}
+		stages = append(stages, pipelineStage{
+			processors: []EntryProcessor{ep},
+			concurrent: concurrent,
+		})
+	}
+	return stages
+}
+
+// startPipeline reads the source in one goroutine and runs the stages
+// connected by bounded queues: each concurrent stage on r.workers goroutines,
+// each sequential stage on its own goroutine, reordering the items first.
+func (r *EntriesReader) startPipeline(ctx context.Context, entryProcessors []EntryProcessor) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan *pipelineItem, r.workers*queuedItemsPerWorker)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(queue)
+		if err := r.read(ctx, queue, entryProcessors); err != nil {
+			fail(err)
+		}
+	}()
+
+	in := queue
+	for i, stage := range splitStages(entryProcessors) {
+		out := make(chan *pipelineItem, r.workers*queuedItemsPerWorker)
+		workers := 1
+		if stage.concurrent {
+			workers = r.workers
+		}
+		var stageWG sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			stageWG.Add(1)
+			go func(in <-chan *pipelineItem, stage pipelineStage, parse bool) {
+				defer stageWG.Done()
+				if err := r.runStage(ctx, in, out, stage, parse); err != nil {
+					fail(err)
+				}
+			}(in, stage, i == 0)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stageWG.Wait()
+			close(out)
+		}()
+		in = out
+	}
+
+	// Drain the last stage.
+	for range in {
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return io.EOF
+}
+
+// read feeds the queue with the lines (or entries, when the fetcher cannot
+// split reading from parsing), the empty items polling the EntryEmitters and,
+// at the end of the input, a final item.
+func (r *EntriesReader) read(ctx context.Context, queue chan<- *pipelineItem, entryProcessors []EntryProcessor) error {
+	items := r.newItemReader(ctx, entryProcessors, true)
+	defer items.stop()
+	for seq := uint64(0); ; seq++ {
+		item, err := items.next(ctx)
+		switch {
+		case errors.Is(err, io.EOF):
+			item = &pipelineItem{final: true}
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			if err := r.errorHandler(ctx, err); err != nil {
+				return err
+			}
+			item = &pipelineItem{}
+		}
+		item.seq = seq
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case queue <- item:
+		}
+		if item.final {
+			return nil
+		}
+	}
+}
+
+// readItem reads the next line or, when the fetcher cannot split reading from
+// parsing (or split is off), the next entry.
+func (r *EntriesReader) readItem(split bool) (*pipelineItem, error) {
+	if lineFetcher, ok := r.fetcher.(LineFetcher); ok && split {
+		line, lineNumber, err := lineFetcher.NextLine()
+		if err != nil {
+			return nil, err
+		}
+		return &pipelineItem{unparsed: true, line: line, lineNumber: lineNumber}, nil
+	}
+	entry, err := r.fetcher.Next()
+	if err != nil {
+		return nil, err
+	}
+	return &pipelineItem{entries: []*domain.Entry{&entry}}, nil
+}
+
+type readResult struct {
+	item *pipelineItem
+	err  error
+}
+
+// itemReader hands out the items read from the source. With EntryEmitters
+// to poll, it reads on a goroutine of its own and, every emit interval
+// without input, hands out an empty item instead.
+type itemReader struct {
+	r      *EntriesReader
+	split  bool
+	items  chan readResult
+	ticker *time.Ticker
+}
+
+// newItemReader starts reading in the background when needed. The reading
+// stops at the end of the input or when ctx is done.
+func (r *EntriesReader) newItemReader(ctx context.Context, entryProcessors []EntryProcessor, split bool) *itemReader {
+	ir := &itemReader{r: r, split: split}
+	if r.emitInterval <= 0 || !hasEmitter(entryProcessors) {
+		return ir
+	}
+	ir.items = make(chan readResult)
+	ir.ticker = time.NewTicker(r.emitInterval)
+	go func() {
+		for {
+			item, err := r.readItem(split)
+			select {
+			case <-ctx.Done():
+				return
+			case ir.items <- readResult{item: item, err: err}:
+			}
+			if errors.Is(err, io.EOF) {
+				return
+			}
+		}
+	}()
+	return ir
+}
+
+func (ir *itemReader) next(ctx context.Context) (*pipelineItem, error) {
+	if ir.items == nil {
+		return ir.r.readItem(ir.split)
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-ir.ticker.C:
+		return &pipelineItem{}, nil
+	case res := <-ir.items:
+		return res.item, res.err
+	}
+}
+
+func (ir *itemReader) stop() {
+	if ir.ticker != nil {
+		ir.ticker.Stop()
+	}
+}
+
+func hasEmitter(entryProcessors []EntryProcessor) bool {
+	for _, ep := range entryProcessors {
+		if _, ok := ep.(EntryEmitter); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// runStage processes the items of in, sending them to out. Sequential stages
+// (one goroutine) process the items in seq order.
+func (r *EntriesReader) runStage(ctx context.Context, in <-chan *pipelineItem, out chan<- *pipelineItem, stage pipelineStage, parse bool) error {
+	send := func(item *pipelineItem) error {
+		if parse && item.unparsed {
+			entry, err := r.fetcher.(LineFetcher).ParseLine(item.line, item.lineNumber)
+			item.unparsed, item.line = false, nil
+			if err != nil {
+				if err := r.errorHandler(ctx, err); err != nil {
+					return err
+				}
+			} else {
+				item.entries = []*domain.Entry{&entry}
+			}
+		}
+		entries, err := r.run(ctx, item.entries, item.final, stage.processors)
+		if err != nil {
+			return err
+		}
+		item.entries = entries
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- item:
+			return nil
+		}
+	}
+
+	if stage.concurrent {
+		for item := range in {
+			if err := send(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	pending := make(map[uint64]*pipelineItem)
+	var next uint64
+	for item := range in {
+		pending[item.seq] = item
+		for {
+			item, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if err := send(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/expr"
+)
+
+// ComputeRule sets Field to the result of Expr, an expression in the syntax
+// of package expr.
+type ComputeRule struct {
+	Field string
+	Expr  string
+}
+
+// ParseComputeRule parses a rule given as "field=expression".
+func ParseComputeRule(s string) (ComputeRule, error) {
+	field, expression, ok := strings.Cut(s, "=")
+	field = strings.TrimSpace(field)
+	if !ok || field == "" || strings.HasPrefix(expression, "=") {
+		return ComputeRule{}, fmt.Errorf("invalid computed field %q: expected field=expression", s)
+	}
+	return ComputeRule{Field: field, Expr: expression}, nil
+}
+
+type computeRule struct {
+	field string
+	expr  *expr.Expr
+}
+
+// Computer adds fields derived from the others (duration_ms from
+// duration_ns, is_slow from a threshold, route_group extracted from a path).
+// Rules run in order, so a rule can use the fields computed by the previous
+// ones. Null results leave the field untouched.
+type Computer struct {
+	rules []computeRule
+}
+
+func NewComputer(rules []ComputeRule) (*Computer, error) {
+	c := &Computer{
+		rules: make([]computeRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		e, err := expr.Compile(rule.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("computed field %s: %w", rule.Field, err)
+		}
+		c.rules = append(c.rules, computeRule{field: rule.Field, expr: e})
+	}
+	return c, nil
+}
+
+// ConcurrentSafe implements service.ConcurrentProcessor.
+func (c *Computer) ConcurrentSafe() {}
+
+func (c *Computer) Process(_ context.Context, entry *domain.Entry) error {
+	lookup := func(path string) (interface{}, bool) {
+		return entryValue(entry, path)
+	}
+	for _, rule := range c.rules {
+		v, err := rule.expr.Eval(lookup)
+		if err != nil {
+			return fmt.Errorf("error computing %s: %w", rule.field, err)
+		}
+		if v != nil {
+			setPath(&entry.OrderedMap, rule.field, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+func TestComputer(t *testing.T) {
+	ctx := context.Background()
+
+	parse := func(t *testing.T, specs ...string) []ComputeRule {
+		rules := make([]ComputeRule, len(specs))
+		for i, spec := range specs {
+			rule, err := ParseComputeRule(spec)
+			require.NoError(t, err)
+			rules[i] = rule
+		}
+		return rules
+	}
+
+	t.Run("should set the computed fields in order", func(t *testing.T) {
+		c, err := NewComputer(parse(t,
+			"duration_ms = duration_ns / 1e6",
+			"is_slow=duration_ms > 500",
+			`http.route_group=extract(http.path, "^/api/v\\d+/([^/]+)")`,
+			"severity=level == 'error' ? 2 : 1",
+			"missing=nothing * 2",
+		))
+		require.NoError(t, err)
+
+		http := orderedmap.New()
+		http.Set("path", "/api/v1/orders/42")
+		entry := domain.NewEntry()
+		entry.Set("lvl", "ERR")
+		entry.Set("duration_ns", float64(750_000_000))
+		entry.Set("http", *http)
+		require.NoError(t, c.Process(ctx, entry))
+
+		get := func(path string) interface{} {
+			v, _ := lookupPath(&entry.OrderedMap, path)
+			return v
+		}
+		assert.Equal(t, 750.0, get("duration_ms"))
+		assert.Equal(t, true, get("is_slow"))
+		assert.Equal(t, "orders", get("http.route_group"))
+		assert.Equal(t, 2.0, get("severity"))
+		_, ok := entry.Get("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("should report evaluation errors", func(t *testing.T) {
+		c, err := NewComputer(parse(t, "x=extract(msg, pattern)"))
+		require.NoError(t, err)
+		entry := domain.NewEntry()
+		entry.Set("msg", "hello")
+		entry.Set("pattern", "(")
+		assert.ErrorContains(t, c.Process(ctx, entry), "error computing x")
+	})
+
+	t.Run("should reject invalid rules", func(t *testing.T) {
+		for _, spec := range []string{"no_equals", "=1", "a==1"} {
+			_, err := ParseComputeRule(spec)
+			assert.Error(t, err, spec)
+		}
+		_, err := NewComputer(parse(t, "a=1 +"))
+		assert.Error(t, err)
+	})
+}
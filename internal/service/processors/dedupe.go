@@ -0,0 +1,170 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/service"
+	"github.com/jamillosantos/lovr/internal/timestamp"
+	"github.com/jamillosantos/lovr/internal/ulid"
+)
+
+const (
+	// FieldRepeatCount holds, in collapsed entries, how many times the entry
+	// was seen in a row.
+	FieldRepeatCount = "repeat_count"
+	// FieldFirstSeen and FieldLastSeen hold, in collapsed entries, the
+	// timestamps of the first and last repetitions.
+	FieldFirstSeen = "first_seen"
+	FieldLastSeen  = "last_seen"
+
+	DefaultDedupeWindow = time.Minute
+)
+
+// DefaultDedupeFields tells repeated messages apart by level.
+var DefaultDedupeFields = []string{FieldLevel}
+
+// DedupeConfig configures a Deduper.
+type DedupeConfig struct {
+	// Fields identify an entry, along with its message. Default:
+	// DefaultDedupeFields.
+	Fields []string
+	// Window is the longest gap between two repetitions for them to be
+	// collapsed. A run no repetition came in for as long, as measured by the
+	// clock, is reported without waiting for the next entry. Default:
+	// DefaultDedupeWindow.
+	Window time.Duration
+}
+
+type dedupeRun struct {
+	key   string
+	entry *domain.Entry
+	count uint64
+	first time.Time
+	last  time.Time
+	// seen is when the last repetition came in, by the clock.
+	seen time.Time
+}
+
+// Deduper collapses runs of consecutive identical entries (same message and
+// Fields, whatever the timestamp). The first entry of a run goes through
+// right away and the repetitions are skipped. When the run ends, the first
+// entry is emitted again, with the same ID, carrying the repeat count and the
+// first and last timestamps in its fields and in Entry.Repeat: the index
+// replaces the original document and Stdout prints a one-line note.
+type Deduper struct {
+	cfg DedupeConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	run     *dedupeRun
+	pending []*domain.Entry
+}
+
+func NewDeduper(cfg DedupeConfig) *Deduper {
+	if len(cfg.Fields) == 0 {
+		cfg.Fields = DefaultDedupeFields
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultDedupeWindow
+	}
+	return &Deduper{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+func (d *Deduper) Process(_ context.Context, entry *domain.Entry) error {
+	values := make([]interface{}, 0, len(d.cfg.Fields)+1)
+	msg, _ := entryValue(entry, FieldMessage)
+	values = append(values, msg)
+	for _, f := range d.cfg.Fields {
+		v, _ := entryValue(entry, f)
+		values = append(values, v)
+	}
+	key := fmt.Sprintf("%q", values)
+
+	now := d.now()
+	ts := now
+	if v, ok := entryValue(entry, FieldTimestamp); ok {
+		if parsed, ok := timestamp.Parse(v); ok {
+			ts = parsed
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.run != nil && d.run.key == key && ts.Sub(d.run.last) <= d.cfg.Window {
+		d.run.count++
+		d.run.last = ts
+		d.run.seen = now
+		return service.ErrSkipEntry
+	}
+
+	if entry.ID == "" {
+		id, err := ulid.New(ts)
+		if err != nil {
+			return fmt.Errorf("failed creating the entry ID: %w", err)
+		}
+		entry.ID = id.String()
+	}
+	previous := d.run
+	d.run = &dedupeRun{
+		key:   key,
+		entry: &domain.Entry{OrderedMap: copyOrderedMap(entry.OrderedMap), Raw: entry.Raw, ID: entry.ID},
+		count: 1,
+		first: ts,
+		last:  ts,
+		seen:  now,
+	}
+	if previous == nil || previous.count == 1 {
+		return nil
+	}
+	// The collapsed entry of the previous run must come out before this one:
+	// hold it back and emit both, in order.
+	d.pending = append(d.pending, collapsedEntry(previous), entry)
+	return service.ErrSkipEntry
+}
+
+// Emit returns the collapsed entry of the run that just ended, if any, and
+// that of the current run when final or when no repetition came in for
+// Window.
+func (d *Deduper) Emit(_ context.Context, final bool) []*domain.Entry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	emitted := d.pending
+	d.pending = nil
+	if d.run != nil && (final || d.now().Sub(d.run.seen) > d.cfg.Window) {
+		if d.run.count > 1 {
+			emitted = append(emitted, collapsedEntry(d.run))
+		}
+		d.run = nil
+	}
+	return emitted
+}
+
+func collapsedEntry(run *dedupeRun) *domain.Entry {
+	entry := run.entry
+	entry.Set(FieldRepeatCount, float64(run.count))
+	entry.Set(FieldFirstSeen, run.first.Format(time.RFC3339Nano))
+	entry.Set(FieldLastSeen, run.last.Format(time.RFC3339Nano))
+	entry.Repeat = &domain.Repeat{Count: run.count, First: run.first, Last: run.last}
+	return entry
+}
+
+// repeatOf reports the repetitions of an entry collapsed by a Deduper. The
+// repeat_count field is not enough: log lines may have one of their own.
+func repeatOf(entry *domain.Entry) (count uint64, first, last string, ok bool) {
+	if entry.Repeat == nil {
+		return 0, "", "", false
+	}
+	return entry.Repeat.Count,
+		entry.Repeat.First.Format(time.RFC3339Nano),
+		entry.Repeat.Last.Format(time.RFC3339Nano),
+		true
+}
@@ -0,0 +1,203 @@
+package processors
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/service"
+)
+
+func TestDeduper(t *testing.T) {
+	ctx := context.Background()
+
+	newEntry := func(ts, level, msg string) *domain.Entry {
+		entry := domain.NewEntry()
+		entry.Set("ts", ts)
+		entry.Set("level", level)
+		entry.Set("msg", msg)
+		return entry
+	}
+	// run feeds the entries to d the way service.EntriesReader does, returning
+	// what reaches the next processor.
+	run := func(t *testing.T, d *Deduper, entries ...*domain.Entry) []*domain.Entry {
+		var out []*domain.Entry
+		for _, entry := range entries {
+			err := d.Process(ctx, entry)
+			if err == nil {
+				out = append(out, entry)
+			} else {
+				require.ErrorIs(t, err, service.ErrSkipEntry)
+			}
+			out = append(out, d.Emit(ctx, false)...)
+		}
+		return append(out, d.Emit(ctx, true)...)
+	}
+	msgs := func(entries []*domain.Entry) []string {
+		r := make([]string, len(entries))
+		for i, entry := range entries {
+			msg, _ := entry.Get("msg")
+			r[i] = msg.(string)
+			if count, _, _, ok := repeatOf(entry); ok {
+				r[i] += "*" + string(rune('0'+count))
+			}
+		}
+		return r
+	}
+
+	t.Run("should collapse consecutive repeats", func(t *testing.T) {
+		d := NewDeduper(DedupeConfig{})
+		out := run(t, d,
+			newEntry("2026-01-01T12:00:00Z", "error", "retrying"),
+			newEntry("2026-01-01T12:00:01Z", "error", "retrying"),
+			newEntry("2026-01-01T12:00:02Z", "error", "retrying"),
+			newEntry("2026-01-01T12:00:03Z", "info", "connected"),
+			newEntry("2026-01-01T12:00:04Z", "info", "serving"),
+			newEntry("2026-01-01T12:00:05Z", "info", "serving"),
+		)
+		assert.Equal(t, []string{"retrying", "retrying*3", "connected", "serving", "serving*2"}, msgs(out))
+
+		collapsed := out[1]
+		assert.Equal(t, out[0].ID, collapsed.ID)
+		assert.NotEmpty(t, collapsed.ID)
+		_, first, last, _ := repeatOf(collapsed)
+		assert.Equal(t, "2026-01-01T12:00:00Z", first)
+		assert.Equal(t, "2026-01-01T12:00:02Z", last)
+	})
+
+	t.Run("should tell entries apart by the configured fields", func(t *testing.T) {
+		d := NewDeduper(DedupeConfig{})
+		out := run(t, d,
+			newEntry("2026-01-01T12:00:00Z", "error", "retrying"),
+			newEntry("2026-01-01T12:00:01Z", "warn", "retrying"),
+		)
+		assert.Equal(t, []string{"retrying", "retrying"}, msgs(out))
+
+		d = NewDeduper(DedupeConfig{Fields: []string{"attempt"}})
+		e1 := newEntry("2026-01-01T12:00:00Z", "error", "retrying")
+		e1.Set("attempt", 1.0)
+		e2 := newEntry("2026-01-01T12:00:01Z", "warn", "retrying")
+		e2.Set("attempt", 1.0)
+		assert.Equal(t, []string{"retrying", "retrying*2"}, msgs(run(t, d, e1, e2)))
+	})
+
+	t.Run("should start a new run after the window", func(t *testing.T) {
+		d := NewDeduper(DedupeConfig{})
+		out := run(t, d,
+			newEntry("2026-01-01T12:00:00Z", "error", "retrying"),
+			newEntry("2026-01-01T12:00:30Z", "error", "retrying"),
+			newEntry("2026-01-01T12:05:00Z", "error", "retrying"),
+		)
+		assert.Equal(t, []string{"retrying", "retrying*2", "retrying"}, msgs(out))
+	})
+
+	t.Run("should report a run that went quiet for the window", func(t *testing.T) {
+		now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		d := NewDeduper(DedupeConfig{Window: 10 * time.Second})
+		d.now = func() time.Time { return now }
+
+		first := newEntry("2026-01-01T12:00:00Z", "error", "retrying")
+		require.NoError(t, d.Process(ctx, first))
+		out := []*domain.Entry{first}
+		require.ErrorIs(t, d.Process(ctx, newEntry("2026-01-01T12:00:01Z", "error", "retrying")), service.ErrSkipEntry)
+		now = now.Add(5 * time.Second)
+		require.ErrorIs(t, d.Process(ctx, newEntry("2026-01-01T12:00:05Z", "error", "retrying")), service.ErrSkipEntry)
+
+		now = now.Add(10 * time.Second)
+		assert.Empty(t, d.Emit(ctx, false))
+		now = now.Add(time.Second)
+		out = append(out, d.Emit(ctx, false)...)
+		assert.Equal(t, []string{"retrying", "retrying*3"}, msgs(out))
+		assert.Empty(t, d.Emit(ctx, true))
+	})
+
+	t.Run("should replace the indexed entry and print a note", func(t *testing.T) {
+		index, err := bleve.NewMemOnly(NewIndexMapping())
+		require.NoError(t, err)
+		defer index.Close()
+		indexer := NewIndexer(index)
+		var buf bytes.Buffer
+		stdout := NewStdout(WithWriter(&buf))
+
+		d := NewDeduper(DedupeConfig{})
+		for _, entry := range run(t, d,
+			newEntry("2026-01-01T12:00:00Z", "error", "retrying"),
+			newEntry("2026-01-01T12:00:01Z", "error", "retrying"),
+		) {
+			require.NoError(t, stdout.Process(ctx, entry))
+			require.NoError(t, indexer.Process(ctx, entry))
+		}
+		require.NoError(t, indexer.Flush())
+
+		count, err := index.DocCount()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), count)
+		q := bleve.NewNumericRangeQuery(floatPtr(2), floatPtr(3))
+		q.SetField(FieldRepeatCount)
+		res, err := index.Search(bleve.NewSearchRequest(q))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), res.Total)
+
+		assert.Contains(t, buf.String(), `"retrying" repeated 2 times from 2026-01-01T12:00:00Z to 2026-01-01T12:00:01Z`)
+	})
+}
+
+// entriesFetcher hands out the entries in order, as a parser would.
+type entriesFetcher []*domain.Entry
+
+func (f *entriesFetcher) Next() (domain.Entry, error) {
+	if len(*f) == 0 {
+		return domain.Entry{}, io.EOF
+	}
+	entry := (*f)[0]
+	*f = (*f)[1:]
+	return *entry, nil
+}
+
+func TestDeduper_workers(t *testing.T) {
+	t.Run("should index the collapsed entries over the originals with many workers", func(t *testing.T) {
+		index, err := bleve.NewMemOnly(NewIndexMapping())
+		require.NoError(t, err)
+		indexer := NewIndexer(index)
+
+		// Runs of 2 to 4 repeats, each replacing the document of its first
+		// entry once collapsed.
+		var entries entriesFetcher
+		runs := 200
+		for i := 0; i < runs; i++ {
+			for j := 0; j < 2+i%3; j++ {
+				entry := domain.NewEntry()
+				entry.Set("ts", time.Date(2026, 1, 1, 12, 0, i, j*1000, time.UTC).Format(time.RFC3339Nano))
+				entry.Set("msg", "message "+strconv.Itoa(i))
+				entries = append(entries, entry)
+			}
+		}
+		r := service.NewEntriesReader(&entries, func(_ context.Context, err error) error {
+			return err
+		}, service.WithWorkers(8))
+		err = r.Start(context.Background(), NewDeduper(DedupeConfig{}), indexer)
+		require.ErrorIs(t, err, io.EOF)
+		require.NoError(t, indexer.Close())
+
+		count, err := index.DocCount()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(runs), count)
+		q := bleve.NewNumericRangeQuery(floatPtr(2), nil)
+		q.SetField(FieldRepeatCount)
+		res, err := index.Search(bleve.NewSearchRequest(q))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(runs), res.Total)
+	})
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,406 @@
+package processors
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iancoleman/orderedmap"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+// EnrichMatch is how the value of a field is looked up in a table.
+type EnrichMatch string
+
+const (
+	// EnrichExact looks the value up as is.
+	EnrichExact EnrichMatch = "exact"
+	// EnrichCIDR looks an IP address up in a table of networks (10.0.0.0/8)
+	// and addresses; the most specific match wins.
+	EnrichCIDR EnrichMatch = "cidr"
+)
+
+// DefaultEnrichReloadInterval is how often the tables are checked for
+// changes.
+const DefaultEnrichReloadInterval = 5 * time.Second
+
+// EnrichRule joins a field of the entries against a lookup table.
+type EnrichRule struct {
+	// Field is the dotted path of the value to look up.
+	Field string `json:"field"`
+	// Table is a CSV file with a header row, a JSON array of objects or a
+	// JSON object of objects (keyed by the looked up value). Relative paths
+	// are relative to the rules file.
+	Table string `json:"table"`
+	// Key is the column holding the looked up values. Default: the first
+	// column (ignored for JSON objects of objects).
+	Key string `json:"key,omitempty"`
+	// Match defaults to EnrichExact.
+	Match EnrichMatch `json:"match,omitempty"`
+	// Columns are the columns added to the entries. Default: all but Key.
+	Columns []string `json:"columns,omitempty"`
+	// Target prefixes the added fields (target.column). Default: the columns
+	// are added at the top level.
+	Target string `json:"target,omitempty"`
+}
+
+// LoadEnrichRules reads a JSON array of EnrichRule from a file.
+func LoadEnrichRules(path string) ([]EnrichRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading enrichment rules: %w", err)
+	}
+	var rules []EnrichRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid enrichment rules in %s: %w", path, err)
+	}
+	for i := range rules {
+		if rules[i].Table != "" && !filepath.IsAbs(rules[i].Table) {
+			rules[i].Table = filepath.Join(filepath.Dir(path), rules[i].Table)
+		}
+	}
+	return rules, nil
+}
+
+// lookupRow is a row of a table: its columns in order.
+type lookupRow = *orderedmap.OrderedMap
+
+type lookupTable struct {
+	exact    map[string]lookupRow
+	prefixes []lookupPrefix
+}
+
+type lookupPrefix struct {
+	prefix netip.Prefix
+	row    lookupRow
+}
+
+func (t *lookupTable) lookup(value interface{}, match EnrichMatch) (lookupRow, bool) {
+	key, ok := lookupKey(value)
+	if !ok {
+		return nil, false
+	}
+	if match != EnrichCIDR {
+		row, ok := t.exact[key]
+		return row, ok
+	}
+	addr, err := netip.ParseAddr(key)
+	if err != nil {
+		return nil, false
+	}
+	addr = addr.Unmap()
+	for _, p := range t.prefixes {
+		if p.prefix.Contains(addr) {
+			return p.row, true
+		}
+	}
+	return nil, false
+}
+
+func lookupKey(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+type enrichRule struct {
+	EnrichRule
+
+	mu        sync.RWMutex
+	table     *lookupTable
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// Enricher annotates entries with context that is not in the logs
+// themselves (user → team, IP → datacenter, service → owner) by joining
+// fields against local lookup tables. The tables are reloaded when their
+// files change; when a reload fails, the previous version is kept and the
+// error is reported, once per reload interval, until the file is fixed.
+type Enricher struct {
+	rules          []*enrichRule
+	reloadInterval time.Duration
+	onReloadError  func(error)
+	now            func() time.Time
+}
+
+type EnricherOption func(*Enricher)
+
+// WithReloadInterval sets how often the tables are checked for changes.
+// Default: DefaultEnrichReloadInterval.
+func WithReloadInterval(interval time.Duration) EnricherOption {
+	return func(e *Enricher) {
+		e.reloadInterval = interval
+	}
+}
+
+// WithReloadErrorHandler sets the function the reload errors are reported
+// to. They are not returned by Process: the entries are still enriched, with
+// the previous version of the table. Default: the errors are ignored.
+func WithReloadErrorHandler(fn func(error)) EnricherOption {
+	return func(e *Enricher) {
+		e.onReloadError = fn
+	}
+}
+
+func NewEnricher(rules []EnrichRule, opts ...EnricherOption) (*Enricher, error) {
+	e := &Enricher{
+		rules:          make([]*enrichRule, 0, len(rules)),
+		reloadInterval: DefaultEnrichReloadInterval,
+		onReloadError:  func(error) {},
+		now:            time.Now,
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	for i, rule := range rules {
+		if rule.Field == "" || rule.Table == "" {
+			return nil, fmt.Errorf("invalid enrichment #%d: field and table are required", i+1)
+		}
+		switch rule.Match {
+		case "":
+			rule.Match = EnrichExact
+		case EnrichExact, EnrichCIDR:
+		default:
+			return nil, fmt.Errorf("invalid enrichment #%d: unknown match %q (exact or cidr)", i+1, rule.Match)
+		}
+		r := &enrichRule{EnrichRule: rule}
+		if err := r.load(e.now()); err != nil {
+			return nil, fmt.Errorf("invalid enrichment #%d: %w", i+1, err)
+		}
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+// ConcurrentSafe implements service.ConcurrentProcessor.
+func (e *Enricher) ConcurrentSafe() {}
+
+func (e *Enricher) Process(_ context.Context, entry *domain.Entry) error {
+	for _, rule := range e.rules {
+		if err := rule.reloadIfChanged(e.now(), e.reloadInterval); err != nil {
+			e.onReloadError(err)
+		}
+		value, ok := lookupPath(&entry.OrderedMap, rule.Field)
+		if !ok {
+			continue
+		}
+		rule.mu.RLock()
+		row, ok := rule.table.lookup(value, rule.Match)
+		rule.mu.RUnlock()
+		if !ok {
+			continue
+		}
+		rule.apply(&entry.OrderedMap, row)
+	}
+	return nil
+}
+
+func (r *enrichRule) apply(m *orderedmap.OrderedMap, row lookupRow) {
+	columns := r.Columns
+	if len(columns) == 0 {
+		columns = row.Keys()
+	}
+	for _, column := range columns {
+		if column == r.Key {
+			continue
+		}
+		v, ok := row.Get(column)
+		if !ok {
+			continue
+		}
+		field := column
+		if r.Target != "" {
+			field = r.Target + "." + column
+		}
+		setPath(m, field, v)
+	}
+}
+
+// reloadIfChanged reloads the table when its file changed, checking at most
+// once per interval.
+func (r *enrichRule) reloadIfChanged(now time.Time, interval time.Duration) error {
+	r.mu.RLock()
+	due := now.Sub(r.lastCheck) >= interval
+	r.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	r.mu.Lock()
+	if now.Sub(r.lastCheck) < interval {
+		// Another goroutine got here first.
+		r.mu.Unlock()
+		return nil
+	}
+	r.lastCheck = now
+	modTime := r.modTime
+	r.mu.Unlock()
+
+	info, err := os.Stat(r.Table)
+	if err != nil {
+		return fmt.Errorf("error checking the lookup table %s (keeping the previous version): %w", r.Table, err)
+	}
+	if info.ModTime().Equal(modTime) {
+		return nil
+	}
+	if err := r.load(now); err != nil {
+		return fmt.Errorf("error reloading the lookup table %s (keeping the previous version): %w", r.Table, err)
+	}
+	return nil
+}
+
+func (r *enrichRule) load(now time.Time) error {
+	info, err := os.Stat(r.Table)
+	if err != nil {
+		return fmt.Errorf("error reading the lookup table: %w", err)
+	}
+	rows, err := readLookupRows(r.Table, r.Key)
+	if err != nil {
+		return err
+	}
+	table, err := newLookupTable(rows, r.Match)
+	if err != nil {
+		return fmt.Errorf("invalid lookup table %s: %w", r.Table, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.table = table
+	r.modTime = info.ModTime()
+	r.lastCheck = now
+	return nil
+}
+
+// keyedRow is a row and the value it is looked up by.
+type keyedRow struct {
+	key string
+	row lookupRow
+}
+
+func newLookupTable(rows []keyedRow, match EnrichMatch) (*lookupTable, error) {
+	t := &lookupTable{}
+	if match != EnrichCIDR {
+		t.exact = make(map[string]lookupRow, len(rows))
+		for _, r := range rows {
+			t.exact[r.key] = r.row
+		}
+		return t, nil
+	}
+	for _, r := range rows {
+		prefix, err := netip.ParsePrefix(r.key)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(r.key)
+			if addrErr != nil {
+				return nil, fmt.Errorf("%q is neither a network nor an address", r.key)
+			}
+			addr = addr.Unmap()
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		t.prefixes = append(t.prefixes, lookupPrefix{prefix: prefix.Masked(), row: r.row})
+	}
+	// Most specific networks first.
+	sort.SliceStable(t.prefixes, func(i, j int) bool {
+		return t.prefixes[i].prefix.Bits() > t.prefixes[j].prefix.Bits()
+	})
+	return t, nil
+}
+
+func readLookupRows(path, key string) ([]keyedRow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the lookup table: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readCSVRows(data, key)
+	}
+	return readJSONRows(data, key)
+}
+
+func readCSVRows(data []byte, key string) ([]keyedRow, error) {
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV lookup table: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	keyIdx := 0
+	if key != "" {
+		keyIdx = -1
+		for i, column := range header {
+			if column == key {
+				keyIdx = i
+			}
+		}
+		if keyIdx < 0 {
+			return nil, fmt.Errorf("column %q not found in the lookup table", key)
+		}
+	}
+	rows := make([]keyedRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := orderedmap.New()
+		for i, column := range header {
+			if i != keyIdx && i < len(record) {
+				row.Set(column, record[i])
+			}
+		}
+		rows = append(rows, keyedRow{key: record[keyIdx], row: row})
+	}
+	return rows, nil
+}
+
+func readJSONRows(data []byte, key string) ([]keyedRow, error) {
+	var objects []orderedmap.OrderedMap
+	if err := json.Unmarshal(data, &objects); err == nil {
+		rows := make([]keyedRow, 0, len(objects))
+		for i := range objects {
+			object := &objects[i]
+			k := key
+			if k == "" && len(object.Keys()) > 0 {
+				k = object.Keys()[0]
+			}
+			v, _ := object.Get(k)
+			lk, ok := lookupKey(v)
+			if !ok {
+				return nil, fmt.Errorf("row #%d has no %q", i+1, k)
+			}
+			object.Delete(k)
+			rows = append(rows, keyedRow{key: lk, row: object})
+		}
+		return rows, nil
+	}
+
+	var byKey orderedmap.OrderedMap
+	if err := json.Unmarshal(data, &byKey); err != nil {
+		return nil, fmt.Errorf("invalid JSON lookup table (an array of objects or an object of objects): %w", err)
+	}
+	rows := make([]keyedRow, 0, len(byKey.Keys()))
+	for _, k := range byKey.Keys() {
+		v, _ := byKey.Get(k)
+		object, ok := v.(orderedmap.OrderedMap)
+		if !ok {
+			return nil, fmt.Errorf("the value of %q is not an object", k)
+		}
+		rows = append(rows, keyedRow{key: k, row: &object})
+	}
+	return rows, nil
+}
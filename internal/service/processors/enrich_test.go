@@ -0,0 +1,139 @@
+package processors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+func TestEnricher(t *testing.T) {
+	ctx := context.Background()
+
+	writeFile := func(t *testing.T, dir, name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	process := func(t *testing.T, e *Enricher, pairs ...interface{}) *domain.Entry {
+		entry := domain.NewEntry()
+		for i := 0; i+1 < len(pairs); i += 2 {
+			entry.Set(pairs[i].(string), pairs[i+1])
+		}
+		require.NoError(t, e.Process(ctx, entry))
+		return entry
+	}
+	get := func(entry *domain.Entry, path string) interface{} {
+		v, _ := lookupPath(&entry.OrderedMap, path)
+		return v
+	}
+
+	t.Run("should join exact values against a CSV table", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "teams.csv", "id,team,manager\n42,payments,alice\n7,search,bob\n")
+		rulesPath := writeFile(t, dir, "enrich.json", `[{"field": "user.id", "table": "teams.csv", "columns": ["team"], "target": "user"}]`)
+		rules, err := LoadEnrichRules(rulesPath)
+		require.NoError(t, err)
+		e, err := NewEnricher(rules)
+		require.NoError(t, err)
+
+		user := orderedmap.New()
+		user.Set("id", float64(42))
+		entry := process(t, e, "msg", "paid", "user", *user)
+		assert.Equal(t, "payments", get(entry, "user.team"))
+		assert.Nil(t, get(entry, "user.manager"))
+
+		entry = process(t, e, "msg", "anonymous")
+		assert.Equal(t, []string{"msg"}, entry.Keys())
+	})
+
+	t.Run("should match IP addresses against networks, most specific first", func(t *testing.T) {
+		dir := t.TempDir()
+		table := writeFile(t, dir, "dcs.json", `[
+			{"network": "10.0.0.0/8", "dc": "us-east"},
+			{"network": "10.20.0.0/16", "dc": "eu-west", "rack": "r1"},
+			{"network": "192.168.1.10", "dc": "office"}
+		]`)
+		e, err := NewEnricher([]EnrichRule{{Field: "client_ip", Table: table, Match: EnrichCIDR}})
+		require.NoError(t, err)
+
+		assert.Equal(t, "us-east", get(process(t, e, "client_ip", "10.1.2.3"), "dc"))
+		entry := process(t, e, "client_ip", "10.20.2.3")
+		assert.Equal(t, "eu-west", get(entry, "dc"))
+		assert.Equal(t, "r1", get(entry, "rack"))
+		assert.Equal(t, "office", get(process(t, e, "client_ip", "192.168.1.10"), "dc"))
+		assert.Nil(t, get(process(t, e, "client_ip", "172.16.0.1"), "dc"))
+		assert.Nil(t, get(process(t, e, "client_ip", "not an ip"), "dc"))
+	})
+
+	t.Run("should read JSON objects keyed by the looked up value", func(t *testing.T) {
+		dir := t.TempDir()
+		table := writeFile(t, dir, "owners.json", `{"api": {"owner": "team-a"}, "worker": {"owner": "team-b"}}`)
+		e, err := NewEnricher([]EnrichRule{{Field: "service", Table: table, Target: "service_info"}})
+		require.NoError(t, err)
+		assert.Equal(t, "team-b", get(process(t, e, "service", "worker"), "service_info.owner"))
+	})
+
+	t.Run("should reload the tables when they change", func(t *testing.T) {
+		dir := t.TempDir()
+		table := writeFile(t, dir, "owners.csv", "service,owner\napi,team-a\n")
+		var reloadErrs []error
+		e, err := NewEnricher([]EnrichRule{{Field: "service", Table: table}},
+			WithReloadInterval(time.Minute),
+			WithReloadErrorHandler(func(err error) {
+				reloadErrs = append(reloadErrs, err)
+			}),
+		)
+		require.NoError(t, err)
+		now := time.Now()
+		e.now = func() time.Time {
+			return now
+		}
+		assert.Equal(t, "team-a", get(process(t, e, "service", "api"), "owner"))
+
+		writeFile(t, dir, "owners.csv", "service,owner\napi,team-z\n")
+		future := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(table, future, future))
+		assert.Equal(t, "team-a", get(process(t, e, "service", "api"), "owner"), "not checked yet")
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, "team-z", get(process(t, e, "service", "api"), "owner"))
+
+		t.Run("keeping the previous version when the reload fails", func(t *testing.T) {
+			writeFile(t, dir, "owners.csv", "service,owner\n\"broken\n")
+			future = future.Add(time.Hour)
+			require.NoError(t, os.Chtimes(table, future, future))
+			now = now.Add(time.Minute)
+
+			assert.Equal(t, "team-z", get(process(t, e, "service", "api"), "owner"))
+			require.Len(t, reloadErrs, 1)
+			assert.ErrorContains(t, reloadErrs[0], "keeping the previous version")
+
+			assert.Equal(t, "team-z", get(process(t, e, "service", "api"), "owner"))
+			assert.Len(t, reloadErrs, 1, "the error is reported once per interval")
+		})
+	})
+
+	t.Run("should reject invalid rules", func(t *testing.T) {
+		dir := t.TempDir()
+		table := writeFile(t, dir, "t.csv", "k,v\na,b\n")
+		for _, rule := range []EnrichRule{
+			{Table: table},
+			{Field: "f"},
+			{Field: "f", Table: table, Match: "fuzzy"},
+			{Field: "f", Table: filepath.Join(dir, "missing.csv")},
+			{Field: "f", Table: table, Key: "missing"},
+			{Field: "f", Table: table, Match: EnrichCIDR},
+		} {
+			_, err := NewEnricher([]EnrichRule{rule})
+			assert.Error(t, err, "%+v", rule)
+		}
+	})
+}
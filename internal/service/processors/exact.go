@@ -0,0 +1,28 @@
+package processors
+
+import (
+	"strings"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/document"
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+// ExactFieldSuffix names the keyword sub-field indexed next to every string
+// field: request_id is searched by words, lowercased, while request_id._exact
+// holds the whole value as logged, for exact and case-sensitive matches.
+const ExactFieldSuffix = "._exact"
+
+// ExactField returns the name of the exact sub-field of a field.
+func ExactField(field string) string {
+	return field + ExactFieldSuffix
+}
+
+// IsExactField reports whether the field is an exact sub-field.
+func IsExactField(field string) bool {
+	return strings.HasSuffix(field, ExactFieldSuffix)
+}
+
+func addExactField(doc *document.Document, analyzer analysis.Analyzer, path, value string, arrayPositions []uint64) {
+	doc.AddField(document.NewTextFieldCustom(ExactField(path), arrayPositions, []byte(value), index.IndexField, analyzer))
+}
@@ -0,0 +1,83 @@
+package processors
+
+import (
+	"sort"
+	"time"
+
+	"github.com/blevesearch/bleve/v2/analysis/datetime/optional"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// The types of the values indexed under a field, as bleve maps them.
+const (
+	FieldTypeString  = "string"
+	FieldTypeNumber  = "number"
+	FieldTypeBoolean = "boolean"
+	// FieldTypeDate is a string bleve parses, and indexes, as a datetime.
+	FieldTypeDate = "date"
+)
+
+// FieldTypesKey is the internal index key under which the Indexer saves the
+// FieldTypes of the entries it indexed.
+var FieldTypesKey = []byte("lovr:field_types")
+
+// FieldTypes maps field names to the types, sorted, of the values indexed
+// under them. Fields logged with different types across entries (status 503
+// and "503") are indexed differently.
+type FieldTypes map[string][]string
+
+// observe records the types of the values of the document built by BuildDoc,
+// reporting whether any was new for its field.
+func (ft FieldTypes) observe(m mapping.IndexMapping, doc map[string]interface{}) bool {
+	changed := false
+	for name, v := range doc {
+		if name == FieldRaw || name == FieldTimestampNanos || name == FieldTimestamp {
+			continue
+		}
+		walkValues(name, v, nil, func(path string, v interface{}, _ []uint64) {
+			t := valueType(m, v)
+			if t == "" {
+				return
+			}
+			types := ft[path]
+			i := sort.SearchStrings(types, t)
+			if i < len(types) && types[i] == t {
+				return
+			}
+			ft[path] = append(types[:i:i], append([]string{t}, types[i:]...)...)
+			changed = true
+		})
+	}
+	return changed
+}
+
+// valueType returns the type bleve's dynamic mapping indexes a JSON value
+// as, or "" for other values.
+func valueType(m mapping.IndexMapping, v interface{}) string {
+	switch vv := v.(type) {
+	case string:
+		if isDate(m, vv) {
+			return FieldTypeDate
+		}
+		return FieldTypeString
+	case float64, int, int64:
+		return FieldTypeNumber
+	case bool:
+		return FieldTypeBoolean
+	case time.Time:
+		return FieldTypeDate
+	default:
+		return ""
+	}
+}
+
+// isDate reports whether bleve's dynamic mapping parses the string as a
+// datetime, with its default parser.
+func isDate(m mapping.IndexMapping, s string) bool {
+	parser := m.DateTimeParserNamed(optional.Name)
+	if parser == nil {
+		return false
+	}
+	_, _, err := parser.ParseDateTime(s)
+	return err == nil
+}
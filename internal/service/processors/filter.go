@@ -8,6 +8,7 @@ import (
 )
 
 // EntryMatcher reports whether an entry matches a filter expression.
+// Implementations must be safe for concurrent use.
 type EntryMatcher interface {
 	Match(ctx context.Context, entry *domain.Entry) (bool, error)
 }
@@ -23,6 +24,9 @@ func NewFilter(matcher EntryMatcher) *Filter {
 	}
 }
 
+// ConcurrentSafe implements service.ConcurrentProcessor.
+func (f *Filter) ConcurrentSafe() {}
+
 func (f *Filter) Process(ctx context.Context, entry *domain.Entry) error {
 	ok, err := f.matcher.Match(ctx, entry)
 	if err != nil {
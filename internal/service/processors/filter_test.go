@@ -5,7 +5,6 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/iancoleman/orderedmap"
 	"github.com/stretchr/testify/require"
 
 	"github.com/jamillosantos/lovr/internal/domain"
@@ -22,7 +21,7 @@ func (f fakeMatcher) Match(_ context.Context, _ *domain.Entry) (bool, error) {
 }
 
 func TestFilter_Process(t *testing.T) {
-	entry := orderedmap.New()
+	entry := domain.NewEntry()
 	entry.Set("level", "info")
 
 	t.Run("should pass a matching entry through", func(t *testing.T) {
@@ -2,8 +2,11 @@ package processors
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/blevesearch/bleve/v2"
@@ -21,51 +24,264 @@ const (
 	FieldCaller     = "caller"
 	FieldStacktrace = "stacktrace"
 
+	// FieldTraceID, FieldSpanID and FieldParentSpanID correlate the entries
+	// of a distributed trace, whatever the spelling in the logs (traceId,
+	// dd.trace_id...).
+	FieldTraceID      = "trace_id"
+	FieldSpanID       = "span_id"
+	FieldParentSpanID = "parent_span_id"
+	// FieldTraceIDNumber, FieldSpanIDNumber and FieldParentSpanIDNumber hold
+	// the trace IDs logged as numbers under the names above, which are mapped
+	// as keywords. Such IDs are not correlated: decoded as float64, 64-bit
+	// ones lose their last digits.
+	FieldTraceIDNumber      = "trace_id_num"
+	FieldSpanIDNumber       = "span_id_num"
+	FieldParentSpanIDNumber = "parent_span_id_num"
+
+	// FieldLevelRaw keeps the level as logged when it differs from the
+	// normalized one (e.g. "WARN" or 40 for "warning").
+	FieldLevelRaw = "level_raw"
+
 	// FieldTimestampNanos carries the full-precision timestamp: bleve
 	// truncates stored datetime values to seconds.
 	FieldTimestampNanos = "timestamp_ns"
+	// FieldRaw carries the line as read from the source. It is stored for
+	// display only, never searched.
+	FieldRaw = "_raw"
 )
 
 // NewIndexMapping builds the bleve mapping for log entries: timestamp as a
-// real datetime (for range windows and sorting) and level as a keyword (for
-// exact matches); everything else is mapped dynamically.
+// real datetime (for range windows and sorting), level and the trace IDs as
+// keywords (for exact matches); everything else is mapped dynamically. Every
+// string value is also indexed whole in its exact sub-field (see ExactField),
+// and as a number when it holds one (see QuantityField).
 func NewIndexMapping() mapping.IndexMapping {
 	doc := bleve.NewDocumentMapping()
 	doc.AddFieldMappingsAt(FieldTimestamp, bleve.NewDateTimeFieldMapping())
 	doc.AddFieldMappingsAt(FieldLevel, bleve.NewKeywordFieldMapping())
+	doc.AddFieldMappingsAt(FieldTraceID, bleve.NewKeywordFieldMapping())
+	doc.AddFieldMappingsAt(FieldSpanID, bleve.NewKeywordFieldMapping())
+	doc.AddFieldMappingsAt(FieldParentSpanID, bleve.NewKeywordFieldMapping())
 
 	storedOnly := bleve.NewKeywordFieldMapping()
 	storedOnly.Index = false
 	storedOnly.IncludeInAll = false
 	doc.AddFieldMappingsAt(FieldTimestampNanos, storedOnly)
+	doc.AddFieldMappingsAt(FieldRaw, storedOnly)
 
 	m := bleve.NewIndexMapping()
 	m.DefaultMapping = doc
-	return m
+	return subFieldMapping{IndexMappingImpl: m}
 }
 
-// Indexer indexes log entries into a bleve index.
+const (
+	DefaultIndexBatchSize     = 1000
+	DefaultIndexFlushInterval = 500 * time.Millisecond
+)
+
+// Indexer indexes log entries into a bleve index. Entries are accumulated in
+// a batch, indexed when it reaches the batch size or, at the latest, a flush
+// interval after its first entry: bulk loads pay the indexing overhead once
+// per batch while live tails still show up promptly. Close flushes what is
+// left.
 type Indexer struct {
-	index bleve.Index
+	index         bleve.Index
+	batchSize     int
+	flushInterval time.Duration
+
+	// flushMu keeps the batches in order: an entry indexed again with the
+	// same ID (see Deduper) must not be overwritten by an older batch.
+	flushMu sync.Mutex
+
+	mu            sync.Mutex
+	batch         *bleve.Batch
+	oldest        time.Time
+	timer         *time.Timer
+	flushing      int
+	flushingSince time.Time
+	indexed       uint64
+	lastFlush     time.Time
+	err           error
+
+	// types are saved in the index with the next batch when they change.
+	types        FieldTypes
+	typesChanged bool
+}
+
+// IndexerStats reports how far behind the index is from the entries read.
+type IndexerStats struct {
+	// Pending is the number of entries processed but not searchable yet.
+	Pending int
+	// Lag is how long the oldest pending entry has been waiting.
+	Lag time.Duration
+	// Indexed is the number of entries indexed so far.
+	Indexed uint64
+	// LastFlush is when the last batch was indexed.
+	LastFlush time.Time
+}
+
+type IndexerOption func(*Indexer)
+
+// WithBatchSize sets how many entries are indexed at once. Default:
+// DefaultIndexBatchSize.
+func WithBatchSize(size int) IndexerOption {
+	return func(s *Indexer) {
+		s.batchSize = size
+	}
 }
 
-func NewIndexer(index bleve.Index) *Indexer {
-	return &Indexer{
-		index: index,
+// WithFlushInterval sets how long an entry may wait for its batch to fill.
+// Default: DefaultIndexFlushInterval.
+func WithFlushInterval(interval time.Duration) IndexerOption {
+	return func(s *Indexer) {
+		s.flushInterval = interval
 	}
 }
 
+func NewIndexer(index bleve.Index, opts ...IndexerOption) *Indexer {
+	s := &Indexer{
+		index:         index,
+		batchSize:     DefaultIndexBatchSize,
+		flushInterval: DefaultIndexFlushInterval,
+		batch:         index.NewBatch(),
+		types:         make(FieldTypes),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// ConcurrentSafe implements service.ConcurrentProcessor.
+func (s *Indexer) ConcurrentSafe() {}
+
+// Process adds the entry to the current batch. Errors of batches flushed in
+// the background are returned by the next call.
 func (s *Indexer) Process(_ context.Context, entry *domain.Entry) error {
 	id, doc, err := BuildDoc(entry)
 	if err != nil {
 		return err
 	}
-	return s.index.Index(id, doc)
+
+	s.mu.Lock()
+	if err := s.err; err != nil {
+		s.err = nil
+		s.mu.Unlock()
+		return err
+	}
+	if err := s.batch.Index(id, doc); err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("error indexing the entry: %w", err)
+	}
+	if s.types.observe(s.index.Mapping(), doc) {
+		s.typesChanged = true
+	}
+	if s.oldest.IsZero() {
+		s.oldest = time.Now()
+		s.timer = time.AfterFunc(s.flushInterval, s.flushInBackground)
+	}
+	full := s.batch.Size() >= s.batchSize
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush indexes the pending entries right away.
+func (s *Indexer) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	return s.flush()
+}
+
+// flush indexes the pending entries, with flushMu held.
+func (s *Indexer) flush() error {
+	s.mu.Lock()
+	batch := s.batch
+	size := batch.Size()
+	if size == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	savingTypes := s.typesChanged
+	if savingTypes {
+		types, _ := json.Marshal(s.types) // Maps of strings always encode.
+		batch.SetInternal(FieldTypesKey, types)
+		s.typesChanged = false
+	}
+	s.batch = s.index.NewBatch()
+	s.flushing, s.flushingSince = size, s.oldest
+	s.oldest = time.Time{}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+
+	err := s.index.Batch(batch)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushing, s.flushingSince = 0, time.Time{}
+	if err != nil {
+		s.typesChanged = s.typesChanged || savingTypes
+		return fmt.Errorf("error indexing %d entries: %w", size, err)
+	}
+	s.indexed += uint64(size)
+	s.lastFlush = time.Now()
+	return nil
+}
+
+// flushInBackground flushes the batch once the flush interval is over. The
+// error is kept, under flushMu, for the next Process or Close call.
+func (s *Indexer) flushInBackground() {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	if err := s.flush(); err != nil {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+	}
+}
+
+// Close flushes the pending entries, returning the error of a background
+// flush no Process call reported yet along with that of the last flush. The
+// index itself is left open.
+func (s *Indexer) Close() error {
+	err := s.Flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		err = errors.Join(s.err, err)
+		s.err = nil
+	}
+	return err
+}
+
+func (s *Indexer) Stats() IndexerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := IndexerStats{
+		Pending:   s.batch.Size() + s.flushing,
+		Indexed:   s.indexed,
+		LastFlush: s.lastFlush,
+	}
+	oldest := s.oldest
+	if !s.flushingSince.IsZero() {
+		oldest = s.flushingSince
+	}
+	if !oldest.IsZero() {
+		stats.Lag = time.Since(oldest)
+	}
+	return stats
 }
 
 // BuildDoc converts a raw entry into the document shape the Indexer stores,
-// returning the generated entry ID. Shared with entryreader.Matcher so the
-// --filter option and the web search agree on semantics by construction.
+// returning the entry ID (entry.ID, or a new one when empty). Shared with
+// entryreader.Matcher so the --filter option and the web search agree on
+// semantics by construction.
 func BuildDoc(entry *domain.Entry) (string, map[string]interface{}, error) {
 	logEntry := mapToLogEntry(entry)
 	if logEntry.Timestamp.IsZero() {
@@ -74,9 +290,13 @@ func BuildDoc(entry *domain.Entry) (string, map[string]interface{}, error) {
 		logEntry.Timestamp = time.Now()
 	}
 
-	id, err := ulid.New(logEntry.Timestamp)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed creating the entry ID: %w", err)
+	id := entry.ID
+	if id == "" {
+		newID, err := ulid.New(logEntry.Timestamp)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed creating the entry ID: %w", err)
+		}
+		id = newID.String()
 	}
 
 	doc := map[string]interface{}{
@@ -91,12 +311,37 @@ func BuildDoc(entry *domain.Entry) (string, map[string]interface{}, error) {
 	if logEntry.Stacktrace != "" {
 		doc[FieldStacktrace] = logEntry.Stacktrace
 	}
+	if logEntry.Raw != "" {
+		doc[FieldRaw] = logEntry.Raw
+	}
+	for field, v := range map[string]string{
+		FieldTraceID:      logEntry.TraceID,
+		FieldSpanID:       logEntry.SpanID,
+		FieldParentSpanID: logEntry.ParentSpanID,
+	} {
+		if v != "" {
+			doc[field] = v
+		}
+	}
 	for _, k := range logEntry.Fields.Keys() {
 		v, _ := logEntry.Fields.Get(k)
+		if _, ok := v.(float64); ok {
+			if numField, ok := numericIDFields[k]; ok {
+				k = numField
+			}
+		}
 		doc[k] = normalizeValue(v)
 	}
 
-	return id.String(), doc, nil
+	return id, doc, nil
+}
+
+// numericIDFields renames the numeric values left under the keyword mapped
+// trace ID fields, which bleve would neither store nor index as numbers.
+var numericIDFields = map[string]string{
+	FieldTraceID:      FieldTraceIDNumber,
+	FieldSpanID:       FieldSpanIDNumber,
+	FieldParentSpanID: FieldParentSpanIDNumber,
 }
 
 // normalizeValue converts orderedmap values (as produced by the JSON parser)
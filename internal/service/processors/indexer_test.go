@@ -0,0 +1,134 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+func TestIndexer(t *testing.T) {
+	ctx := context.Background()
+
+	newIndex := func(t *testing.T) bleve.Index {
+		index, err := bleve.NewMemOnly(NewIndexMapping())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = index.Close()
+		})
+		return index
+	}
+	process := func(t *testing.T, indexer *Indexer, n int) {
+		for i := 0; i < n; i++ {
+			entry := domain.NewEntry()
+			entry.Set("level", "info")
+			entry.Set("msg", fmt.Sprintf("message %d", i))
+			require.NoError(t, indexer.Process(ctx, entry))
+		}
+	}
+	docCount := func(t *testing.T, index bleve.Index) uint64 {
+		count, err := index.DocCount()
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("should index full batches right away", func(t *testing.T) {
+		index := newIndex(t)
+		indexer := NewIndexer(index, WithBatchSize(10), WithFlushInterval(time.Hour))
+		process(t, indexer, 25)
+		assert.Equal(t, uint64(20), docCount(t, index))
+
+		stats := indexer.Stats()
+		assert.Equal(t, 5, stats.Pending)
+		assert.Equal(t, uint64(20), stats.Indexed)
+		assert.Positive(t, stats.Lag)
+
+		require.NoError(t, indexer.Close())
+		assert.Equal(t, uint64(25), docCount(t, index))
+		stats = indexer.Stats()
+		assert.Zero(t, stats.Pending)
+		assert.Zero(t, stats.Lag)
+	})
+
+	t.Run("should index partial batches after the flush interval", func(t *testing.T) {
+		index := newIndex(t)
+		indexer := NewIndexer(index, WithFlushInterval(10*time.Millisecond))
+		process(t, indexer, 3)
+		assert.Eventually(t, func() bool {
+			return docCount(t, index) == 3
+		}, time.Second, 5*time.Millisecond)
+	})
+	t.Run("should report the failed background flushes when closing", func(t *testing.T) {
+		index, err := bleve.NewMemOnly(NewIndexMapping())
+		require.NoError(t, err)
+		indexer := NewIndexer(index, WithFlushInterval(10*time.Millisecond))
+		process(t, indexer, 3)
+		require.NoError(t, index.Close())
+		assert.Eventually(t, func() bool {
+			return indexer.Stats().Pending == 0
+		}, time.Second, 5*time.Millisecond)
+
+		assert.ErrorContains(t, indexer.Close(), "error indexing 3 entries")
+		assert.NoError(t, indexer.Close(), "reported once")
+	})
+
+	t.Run("should save the types of the fields with the batches", func(t *testing.T) {
+		index := newIndex(t)
+		indexer := NewIndexer(index)
+		for _, fields := range []map[string]interface{}{
+			{"status": 503.0, "ok": true, "created": "2025-06-01T00:00:00Z", "tags": []interface{}{"a", 1.0}},
+			{"status": "503", "user": map[string]interface{}{"id": 42.0}},
+			{"status": 200.0, "created": "yesterday"},
+		} {
+			entry := domain.NewEntry()
+			for k, v := range fields {
+				entry.Set(k, v)
+			}
+			require.NoError(t, indexer.Process(ctx, entry))
+		}
+		require.NoError(t, indexer.Flush())
+
+		data, err := index.GetInternal(FieldTypesKey)
+		require.NoError(t, err)
+		var types FieldTypes
+		require.NoError(t, json.Unmarshal(data, &types))
+		assert.Equal(t, []string{FieldTypeNumber, FieldTypeString}, types["status"])
+		assert.Equal(t, []string{FieldTypeBoolean}, types["ok"])
+		assert.Equal(t, []string{FieldTypeDate, FieldTypeString}, types["created"])
+		assert.Equal(t, []string{FieldTypeNumber, FieldTypeString}, types["tags"])
+		assert.Equal(t, []string{FieldTypeNumber}, types["user.id"])
+		assert.Equal(t, []string{FieldTypeString}, types[FieldLevel])
+		assert.NotContains(t, types, FieldTimestamp)
+	})
+
+	t.Run("should store the trace IDs logged as numbers as numbers", func(t *testing.T) {
+		index := newIndex(t)
+		indexer := NewIndexer(index)
+		entry := domain.NewEntry()
+		entry.Set("msg", "hello")
+		entry.Set("trace_id", 12345.0)
+		entry.Set("span_id", 7.0)
+		require.NoError(t, indexer.Process(ctx, entry))
+		require.NoError(t, indexer.Flush())
+
+		q := bleve.NewNumericRangeQuery(floatPtr(1), nil)
+		q.SetField(FieldTraceIDNumber)
+		req := bleve.NewSearchRequest(q)
+		req.Fields = []string{"*"}
+		res, err := index.Search(req)
+		require.NoError(t, err)
+		require.Len(t, res.Hits, 1)
+		fields := res.Hits[0].Fields
+		assert.Equal(t, 12345.0, fields[FieldTraceIDNumber])
+		assert.Equal(t, 7.0, fields[FieldSpanIDNumber])
+		assert.Equal(t, "hello", fields[FieldMessage])
+		assert.NotContains(t, fields, FieldTraceID)
+	})
+}
@@ -0,0 +1,211 @@
+package processors
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/orderedmap"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+// OutputFormat selects how Output encodes entries.
+type OutputFormat string
+
+const (
+	// OutputJSON writes the entries as a JSON array.
+	OutputJSON OutputFormat = "json"
+	// OutputNDJSON writes one JSON object per line.
+	OutputNDJSON OutputFormat = "ndjson"
+	// OutputLogfmt writes one line of key=value pairs per entry, nested
+	// objects as dotted keys.
+	OutputLogfmt OutputFormat = "logfmt"
+	// OutputCSV writes a header and one row per entry with the columns.
+	OutputCSV OutputFormat = "csv"
+)
+
+// DefaultOutputColumns are the CSV columns when none are chosen.
+var DefaultOutputColumns = []string{FieldTimestamp, FieldLevel, FieldMessage}
+
+// ParseOutputFormat validates an output format name given by the user.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(s); f {
+	case OutputJSON, OutputNDJSON, OutputLogfmt, OutputCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", s)
+	}
+}
+
+// Output writes the normalized entries for other programs: the timestamp in
+// RFC 3339, the canonical level and the message under fixed keys, followed by
+// the trace IDs, caller, stack trace and the remaining fields as logged.
+type Output struct {
+	w       io.Writer
+	format  OutputFormat
+	columns []string
+	csv     *csv.Writer
+	count   int
+}
+
+type OutputOption func(*Output)
+
+// WithOutputWriter sets where the entries are written. Default: os.Stdout.
+func WithOutputWriter(w io.Writer) OutputOption {
+	return func(o *Output) {
+		o.w = w
+	}
+}
+
+// WithColumns sets the CSV columns, as dotted paths of the normalized entry.
+// Default: DefaultOutputColumns.
+func WithColumns(columns []string) OutputOption {
+	return func(o *Output) {
+		o.columns = columns
+	}
+}
+
+func NewOutput(format OutputFormat, opts ...OutputOption) *Output {
+	o := &Output{
+		w:       os.Stdout,
+		format:  format,
+		columns: DefaultOutputColumns,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if format == OutputCSV {
+		o.csv = csv.NewWriter(o.w)
+	}
+	return o
+}
+
+// Process writes the entry. The entries collapsed by a Deduper are skipped:
+// the first entry of their run was written already.
+func (o *Output) Process(_ context.Context, entry *domain.Entry) error {
+	if _, _, _, repeated := repeatOf(entry); repeated {
+		return nil
+	}
+	normalized := normalizeEntry(entry)
+	switch o.format {
+	case OutputCSV:
+		return o.writeCSV(normalized)
+	case OutputLogfmt:
+		pairs := compactPairs("", normalized, nil)
+		fields := make([]string, len(pairs))
+		for i, pair := range pairs {
+			fields[i] = pair.Key + "=" + formatCompactValue(pair.Value)
+		}
+		_, err := fmt.Fprintln(o.w, strings.Join(fields, " "))
+		return err
+	}
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return fmt.Errorf("error encoding the entry: %w", err)
+	}
+	if o.format == OutputJSON {
+		prefix := ",\n"
+		if o.count == 0 {
+			prefix = "[\n"
+		}
+		data = append([]byte(prefix), data...)
+	} else {
+		data = append(data, '\n')
+	}
+	o.count++
+	_, err = o.w.Write(data)
+	return err
+}
+
+func (o *Output) writeCSV(normalized orderedmap.OrderedMap) error {
+	if o.count == 0 {
+		if err := o.csv.Write(o.columns); err != nil {
+			return err
+		}
+	}
+	o.count++
+	row := make([]string, len(o.columns))
+	for i, column := range o.columns {
+		if v, ok := lookupPath(&normalized, column); ok {
+			row[i] = formatCSVValue(v)
+		}
+	}
+	if err := o.csv.Write(row); err != nil {
+		return err
+	}
+	// Rows are flushed one by one, so tails show up as they come.
+	o.csv.Flush()
+	return o.csv.Error()
+}
+
+// Close ends the output once the input is over: it closes the JSON array
+// and writes the CSV header when no entry was written.
+func (o *Output) Close() error {
+	switch {
+	case o.format == OutputJSON && o.count == 0:
+		_, err := fmt.Fprintln(o.w, "[]")
+		return err
+	case o.format == OutputJSON:
+		_, err := fmt.Fprintln(o.w, "\n]")
+		return err
+	case o.format == OutputCSV && o.count == 0:
+		if err := o.csv.Write(o.columns); err != nil {
+			return err
+		}
+		o.csv.Flush()
+		return o.csv.Error()
+	}
+	return nil
+}
+
+// normalizeEntry lays the entry out with the well-known keys under their
+// canonical names, the way the search names them.
+func normalizeEntry(entry *domain.Entry) orderedmap.OrderedMap {
+	logEntry := mapToLogEntry(entry)
+	normalized := orderedmap.New()
+	if !logEntry.Timestamp.IsZero() {
+		normalized.Set(FieldTimestamp, logEntry.Timestamp.Format(time.RFC3339Nano))
+	}
+	if logEntry.Level != "" {
+		normalized.Set(FieldLevel, string(logEntry.Level))
+	}
+	normalized.Set(FieldMessage, logEntry.Message)
+	for _, field := range []struct{ key, value string }{
+		{FieldTraceID, logEntry.TraceID},
+		{FieldSpanID, logEntry.SpanID},
+		{FieldParentSpanID, logEntry.ParentSpanID},
+		{FieldCaller, logEntry.Caller},
+		{FieldStacktrace, logEntry.Stacktrace},
+	} {
+		if field.value != "" {
+			normalized.Set(field.key, field.value)
+		}
+	}
+	for _, k := range logEntry.Fields.Keys() {
+		v, _ := logEntry.Fields.Get(k)
+		normalized.Set(k, v)
+	}
+	return *normalized
+}
+
+// formatCSVValue renders a cell: strings as they are, numbers without
+// exponents, arrays and objects as JSON.
+func formatCSVValue(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64)
+	default:
+		return formatCompactValue(vv)
+	}
+}
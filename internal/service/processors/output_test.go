@@ -0,0 +1,122 @@
+package processors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+func TestOutput_Process(t *testing.T) {
+	newEntries := func() []*domain.Entry {
+		first := domain.NewEntry()
+		first.Set("ts", "2024-03-01T10:20:30.123Z")
+		first.Set("lvl", "WARN")
+		first.Set("msg", "slow request")
+		first.Set("traceId", "abc")
+		http := orderedmap.New()
+		http.Set("status", float64(200))
+		first.Set("http", *http)
+
+		second := domain.NewEntry()
+		second.Set("level", "error")
+		second.Set("msg", "failed, retrying")
+		return []*domain.Entry{first, second}
+	}
+	write := func(t *testing.T, format OutputFormat, opts ...OutputOption) string {
+		var buf bytes.Buffer
+		o := NewOutput(format, append(opts, WithOutputWriter(&buf))...)
+		for _, entry := range newEntries() {
+			require.NoError(t, o.Process(context.Background(), entry))
+		}
+		require.NoError(t, o.Close())
+		return buf.String()
+	}
+
+	t.Run("should write the normalized entries as NDJSON", func(t *testing.T) {
+		assert.Equal(t, `{"timestamp":"2024-03-01T10:20:30.123Z","level":"warning","message":"slow request","trace_id":"abc","http":{"status":200},"level_raw":"WARN"}
+{"level":"error","message":"failed, retrying"}
+`, write(t, OutputNDJSON))
+	})
+
+	t.Run("should write a JSON array", func(t *testing.T) {
+		assert.Equal(t, `[
+{"timestamp":"2024-03-01T10:20:30.123Z","level":"warning","message":"slow request","trace_id":"abc","http":{"status":200},"level_raw":"WARN"},
+{"level":"error","message":"failed, retrying"}
+]
+`, write(t, OutputJSON))
+	})
+
+	t.Run("should write an empty JSON array without entries", func(t *testing.T) {
+		var buf bytes.Buffer
+		o := NewOutput(OutputJSON, WithOutputWriter(&buf))
+		require.NoError(t, o.Close())
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("should write logfmt", func(t *testing.T) {
+		assert.Equal(t, `timestamp=2024-03-01T10:20:30.123Z level=warning message="slow request" trace_id=abc http.status=200 level_raw=WARN
+level=error message="failed, retrying"
+`, write(t, OutputLogfmt))
+	})
+
+	t.Run("should write the CSV columns", func(t *testing.T) {
+		assert.Equal(t, `level,message,http.status
+warning,slow request,200
+error,"failed, retrying",
+`, write(t, OutputCSV, WithColumns([]string{"level", "message", "http.status"})))
+	})
+
+	t.Run("should write the CSV header without entries", func(t *testing.T) {
+		var buf bytes.Buffer
+		o := NewOutput(OutputCSV, WithOutputWriter(&buf))
+		require.NoError(t, o.Close())
+		assert.Equal(t, "timestamp,level,message\n", buf.String())
+	})
+}
+
+func TestOutput_Process_dedupe(t *testing.T) {
+	ctx := context.Background()
+	for _, format := range []OutputFormat{OutputNDJSON, OutputLogfmt, OutputCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			o := NewOutput(format, WithOutputWriter(&buf), WithColumns([]string{"message"}))
+			d := NewDeduper(DedupeConfig{})
+			write := func(entries ...*domain.Entry) {
+				for _, entry := range entries {
+					require.NoError(t, o.Process(ctx, entry))
+				}
+			}
+			for i := 0; i < 3; i++ {
+				entry := domain.NewEntry()
+				entry.Set("ts", fmt.Sprintf("2026-01-01T12:00:0%dZ", i))
+				entry.Set("msg", "retrying")
+				if err := d.Process(ctx, entry); err == nil {
+					write(entry)
+				}
+				write(d.Emit(ctx, false)...)
+			}
+			write(d.Emit(ctx, true)...)
+			require.NoError(t, o.Close())
+
+			assert.Equal(t, 1, strings.Count(buf.String(), "retrying"), buf.String())
+			assert.NotContains(t, buf.String(), FieldRepeatCount)
+		})
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	f, err := ParseOutputFormat("ndjson")
+	require.NoError(t, err)
+	assert.Equal(t, OutputNDJSON, f)
+
+	_, err = ParseOutputFormat("xml")
+	assert.Error(t, err)
+}
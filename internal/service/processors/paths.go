@@ -0,0 +1,127 @@
+package processors
+
+import (
+	"strings"
+
+	"github.com/iancoleman/orderedmap"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+// Entry keys may hold dots themselves ("dependency.service") or be nested
+// objects ({"dependency": {"service": ...}}); both are addressed by the same
+// dotted path, the way bleve names them when indexing. The literal key wins
+// when both exist.
+
+// lookupPath returns the value at a dotted path.
+func lookupPath(m *orderedmap.OrderedMap, path string) (interface{}, bool) {
+	if v, ok := m.Get(path); ok {
+		return v, true
+	}
+	for i := strings.IndexByte(path, '.'); i >= 0; i = nextDot(path, i) {
+		v, ok := m.Get(path[:i])
+		if !ok {
+			continue
+		}
+		if nested, ok := v.(orderedmap.OrderedMap); ok {
+			if found, ok := lookupPath(&nested, path[i+1:]); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// setPath replaces the value at a dotted path. Missing paths are created as a
+// literal (dotted) key at the top level.
+func setPath(m *orderedmap.OrderedMap, path string, value interface{}) {
+	found := updatePath(m, path, func(parent *orderedmap.OrderedMap, key string) {
+		parent.Set(key, value)
+	})
+	if !found {
+		m.Set(path, value)
+	}
+}
+
+// deletePath removes the value at a dotted path, reporting whether it
+// existed.
+func deletePath(m *orderedmap.OrderedMap, path string) bool {
+	return updatePath(m, path, func(parent *orderedmap.OrderedMap, key string) {
+		parent.Delete(key)
+	})
+}
+
+// updatePath finds an existing path and calls apply with the map holding its
+// last key. Nested maps are written back to their parents, as they are held
+// by value.
+func updatePath(m *orderedmap.OrderedMap, path string, apply func(parent *orderedmap.OrderedMap, key string)) bool {
+	if _, ok := m.Get(path); ok {
+		apply(m, path)
+		return true
+	}
+	for i := strings.IndexByte(path, '.'); i >= 0; i = nextDot(path, i) {
+		v, ok := m.Get(path[:i])
+		if !ok {
+			continue
+		}
+		nested, ok := v.(orderedmap.OrderedMap)
+		if !ok {
+			continue
+		}
+		if updatePath(&nested, path[i+1:], apply) {
+			m.Set(path[:i], nested)
+			return true
+		}
+	}
+	return false
+}
+
+func nextDot(path string, after int) int {
+	i := strings.IndexByte(path[after+1:], '.')
+	if i < 0 {
+		return -1
+	}
+	return after + 1 + i
+}
+
+// entryValue returns the value of a field as searched and displayed:
+// message, level, timestamp and the trace IDs resolve their source keys (msg,
+// lvl, ts, traceId...), the level normalized; any other name is a dotted path.
+func entryValue(entry *domain.Entry, field string) (interface{}, bool) {
+	switch field {
+	case FieldMessage:
+		v, _, ok := getValue(&entry.OrderedMap, messageKeys...)
+		return v, ok
+	case FieldLevel:
+		v, _, ok := getValue(&entry.OrderedMap, levelKeys...)
+		if !ok {
+			return nil, false
+		}
+		level, _ := domain.NormalizeLevel(v)
+		return string(level), true
+	case FieldTimestamp:
+		v, _, ok := getTS(&entry.OrderedMap)
+		return v, ok
+	case FieldTraceID:
+		return lookupID(&entry.OrderedMap, traceIDKeys)
+	case FieldSpanID:
+		return lookupID(&entry.OrderedMap, spanIDKeys)
+	case FieldParentSpanID:
+		return lookupID(&entry.OrderedMap, parentSpanIDKeys)
+	default:
+		return lookupPath(&entry.OrderedMap, field)
+	}
+}
+
+// lookupID returns the first of paths found in m with a string value, as
+// extractID does.
+func lookupID(m *orderedmap.OrderedMap, paths []string) (interface{}, bool) {
+	for _, path := range paths {
+		if v, ok := lookupPath(m, path); ok {
+			if id, ok := v.(string); ok && id != "" {
+				return id, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,45 @@
+package processors
+
+import (
+	"strings"
+
+	"github.com/blevesearch/bleve/v2/document"
+	index "github.com/blevesearch/bleve_index_api"
+
+	"github.com/jamillosantos/lovr/internal/units"
+)
+
+// Strings holding quantities are also indexed as numbers, normalized to the
+// base unit of their kind, in a sub-field named after it: status "503" in
+// status._num, latency "152ms" in latency._duration (nanoseconds) and size
+// "1.2MB" in size._bytes. Range queries on them compare values logged with
+// different units.
+var quantitySuffixes = map[units.Kind]string{
+	units.Number:   "._num",
+	units.Duration: "._duration",
+	units.Bytes:    "._bytes",
+}
+
+// QuantityField returns the name of the sub-field holding the quantities of
+// the given kind found in a field.
+func QuantityField(field string, kind units.Kind) string {
+	return field + quantitySuffixes[kind]
+}
+
+// IsQuantityField reports whether the field is a quantity sub-field.
+func IsQuantityField(field string) bool {
+	for _, suffix := range quantitySuffixes {
+		if strings.HasSuffix(field, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func addQuantityField(doc *document.Document, path, value string, arrayPositions []uint64) {
+	q, ok := units.Parse(value)
+	if !ok {
+		return
+	}
+	doc.AddField(document.NewNumericFieldWithIndexingOptions(QuantityField(path, q.Kind), arrayPositions, q.Value, index.IndexField))
+}
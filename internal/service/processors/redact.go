@@ -0,0 +1,417 @@
+package processors
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/iancoleman/orderedmap"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+// RedactAction is what happens to a value selected by a RedactRule.
+type RedactAction string
+
+const (
+	// RedactMask replaces the value (or the matched text) with a fixed marker.
+	RedactMask RedactAction = "mask"
+	// RedactHash replaces the value (or the matched text) with an
+	// HMAC-SHA256 digest, so equal secrets can still be correlated. The key
+	// is random unless set with WithRedactKey: an unkeyed hash of an email or
+	// a card number is reversed by brute force.
+	RedactHash RedactAction = "hash"
+	// RedactDrop removes the whole field holding the value.
+	RedactDrop RedactAction = "drop"
+)
+
+const redactedMarker = "[REDACTED]"
+
+// RedactRule selects values to redact. Exactly one of Fields, Pattern or
+// Detector must be set.
+type RedactRule struct {
+	// Name identifies the rule in the redaction counts. Defaults to the
+	// detector name, or to the fields/pattern.
+	Name string `json:"name,omitempty"`
+	// Fields are keys, matched at any depth, or dotted paths from the root
+	// (nested.key). Matching is case-insensitive. The whole value is redacted.
+	Fields []string `json:"fields,omitempty"`
+	// Pattern is a regular expression searched in every string value; only
+	// the matched text is redacted (unless the action is drop).
+	Pattern string `json:"pattern,omitempty"`
+	// Detector is one of the built-in detectors (see RedactDetectors).
+	Detector string `json:"detector,omitempty"`
+	// Action defaults to RedactMask.
+	Action RedactAction `json:"action,omitempty"`
+}
+
+type redactDetector struct {
+	re *regexp.Regexp
+	// valid further checks a match (e.g. the Luhn checksum).
+	valid func(match string) bool
+}
+
+var redactDetectors = map[string]redactDetector{
+	"jwt": {
+		re: regexp.MustCompile(`eyJ[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]*`),
+	},
+	"aws-key": {
+		re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[A-Z0-9]{16}\b`),
+	},
+	"email": {
+		re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	"credit-card": {
+		re:    regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		valid: luhnValid,
+	},
+}
+
+// RedactDetectors lists the built-in detector names.
+func RedactDetectors() []string {
+	names := make([]string, 0, len(redactDetectors))
+	for name := range redactDetectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultRedactRules masks everything the built-in detectors find.
+func DefaultRedactRules() []RedactRule {
+	names := RedactDetectors()
+	rules := make([]RedactRule, len(names))
+	for i, name := range names {
+		rules[i] = RedactRule{Detector: name}
+	}
+	return rules
+}
+
+// LoadRedactRules reads a JSON array of RedactRule from a file.
+func LoadRedactRules(path string) ([]RedactRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading redaction rules: %w", err)
+	}
+	var rules []RedactRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid redaction rules in %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+type redactRule struct {
+	name   string
+	action RedactAction
+	// keys match a key at any depth, paths a dotted path from the root; both
+	// lowercased.
+	keys  map[string]struct{}
+	paths map[string]struct{}
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+// Redactor removes secrets and personal data from entries before they are
+// displayed or indexed. It must run before processors.Stdout and
+// processors.Indexer. When an entry changes, its raw line is replaced by the
+// redacted entry encoded as JSON, so the original never leaks through it.
+type Redactor struct {
+	rules []redactRule
+	key   []byte
+
+	countsM sync.Mutex
+	counts  map[string]uint64
+}
+
+type RedactorOption func(*Redactor)
+
+// WithRedactKey sets the key of the hash action, so digests can be compared
+// across runs. Default: a random key, different on every run.
+func WithRedactKey(key []byte) RedactorOption {
+	return func(r *Redactor) {
+		r.key = key
+	}
+}
+
+func NewRedactor(rules []RedactRule, opts ...RedactorOption) (*Redactor, error) {
+	r := &Redactor{
+		rules:  make([]redactRule, 0, len(rules)),
+		counts: make(map[string]uint64, len(rules)),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if len(r.key) == 0 {
+		r.key = make([]byte, 32)
+		if _, err := rand.Read(r.key); err != nil {
+			return nil, fmt.Errorf("error generating the redaction key: %w", err)
+		}
+	}
+	for i, rule := range rules {
+		compiled, err := compileRedactRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule #%d: %w", i+1, err)
+		}
+		r.rules = append(r.rules, compiled)
+	}
+	return r, nil
+}
+
+func compileRedactRule(rule RedactRule) (redactRule, error) {
+	compiled := redactRule{
+		name:   rule.Name,
+		action: rule.Action,
+	}
+	switch compiled.action {
+	case "":
+		compiled.action = RedactMask
+	case RedactMask, RedactHash, RedactDrop:
+	default:
+		return compiled, fmt.Errorf("unknown action %q", rule.Action)
+	}
+
+	set := 0
+	if len(rule.Fields) > 0 {
+		set++
+		compiled.keys = make(map[string]struct{})
+		compiled.paths = make(map[string]struct{})
+		for _, f := range rule.Fields {
+			f = strings.ToLower(f)
+			if strings.Contains(f, ".") {
+				compiled.paths[f] = struct{}{}
+			} else {
+				compiled.keys[f] = struct{}{}
+			}
+		}
+		if compiled.name == "" {
+			compiled.name = strings.Join(rule.Fields, ",")
+		}
+	}
+	if rule.Pattern != "" {
+		set++
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return compiled, fmt.Errorf("invalid pattern: %w", err)
+		}
+		compiled.re = re
+		if compiled.name == "" {
+			compiled.name = rule.Pattern
+		}
+	}
+	if rule.Detector != "" {
+		set++
+		d, ok := redactDetectors[rule.Detector]
+		if !ok {
+			return compiled, fmt.Errorf("unknown detector %q (available: %s)", rule.Detector, strings.Join(RedactDetectors(), ", "))
+		}
+		compiled.re = d.re
+		compiled.valid = d.valid
+		if compiled.name == "" {
+			compiled.name = rule.Detector
+		}
+	}
+	if set != 1 {
+		return compiled, fmt.Errorf("exactly one of fields, pattern or detector must be set")
+	}
+	return compiled, nil
+}
+
+// ConcurrentSafe implements service.ConcurrentProcessor.
+func (r *Redactor) ConcurrentSafe() {}
+
+func (r *Redactor) Process(_ context.Context, entry *domain.Entry) error {
+	hits := make(map[string]uint64)
+	r.redactMap(&entry.OrderedMap, "", hits)
+	if len(hits) == 0 {
+		return nil
+	}
+
+	if entry.Raw != "" {
+		data, err := json.Marshal(entry.OrderedMap)
+		if err != nil {
+			return fmt.Errorf("error encoding the redacted entry: %w", err)
+		}
+		entry.Raw = string(data)
+	}
+
+	r.countsM.Lock()
+	for name, n := range hits {
+		r.counts[name] += n
+	}
+	r.countsM.Unlock()
+	return nil
+}
+
+// RedactText redacts free text, such as the lines that could not be parsed,
+// with the pattern and detector rules; field rules need a parsed entry and
+// are skipped. Matches of drop rules are masked, as there is no field to
+// drop.
+func (r *Redactor) RedactText(s string) string {
+	hits := make(map[string]uint64)
+	for _, rule := range r.rules {
+		if rule.re == nil {
+			continue
+		}
+		action := rule.action
+		if action == RedactDrop {
+			action = RedactMask
+		}
+		s = rule.re.ReplaceAllStringFunc(s, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			hits[rule.name]++
+			return r.redactText(match, action)
+		})
+	}
+	if len(hits) > 0 {
+		r.countsM.Lock()
+		for name, n := range hits {
+			r.counts[name] += n
+		}
+		r.countsM.Unlock()
+	}
+	return s
+}
+
+// Counts returns how many values each rule redacted so far, by rule name.
+func (r *Redactor) Counts() map[string]uint64 {
+	r.countsM.Lock()
+	defer r.countsM.Unlock()
+	counts := make(map[string]uint64, len(r.counts))
+	for k, v := range r.counts {
+		counts[k] = v
+	}
+	return counts
+}
+
+// redactMap redacts m in place. path is the dotted, lowercased path of m.
+func (r *Redactor) redactMap(m *orderedmap.OrderedMap, path string, hits map[string]uint64) {
+	// Keys is the map's own slice, which Delete rearranges: iterate a copy.
+	keys := append([]string(nil), m.Keys()...)
+	for _, k := range keys {
+		v, _ := m.Get(k)
+		keyPath := strings.ToLower(k)
+		if path != "" {
+			keyPath = path + "." + keyPath
+		}
+		newValue, drop := r.redactValue(v, strings.ToLower(k), keyPath, hits)
+		if drop {
+			m.Delete(k)
+			continue
+		}
+		m.Set(k, newValue)
+	}
+}
+
+// redactValue returns the redacted value and whether the field holding it
+// must be dropped.
+func (r *Redactor) redactValue(v interface{}, key, path string, hits map[string]uint64) (interface{}, bool) {
+	for _, rule := range r.rules {
+		if rule.keys == nil {
+			continue
+		}
+		_, byKey := rule.keys[key]
+		_, byPath := rule.paths[path]
+		if !byKey && !byPath {
+			continue
+		}
+		hits[rule.name]++
+		if rule.action == RedactDrop {
+			return nil, true
+		}
+		return r.redactText(fmt.Sprint(v), rule.action), false
+	}
+
+	switch vv := v.(type) {
+	case orderedmap.OrderedMap:
+		r.redactMap(&vv, path, hits)
+		return vv, false
+	case []interface{}:
+		for i, item := range vv {
+			newItem, drop := r.redactValue(item, key, path, hits)
+			if drop {
+				return nil, true
+			}
+			vv[i] = newItem
+		}
+		return vv, false
+	case string:
+		return r.redactString(vv, hits)
+	case float64:
+		// Card numbers are also logged as numbers: the value is replaced by
+		// the redacted text when the patterns find something in it.
+		s := strconv.FormatFloat(vv, 'f', -1, 64)
+		redacted, drop := r.redactString(s, hits)
+		if drop || redacted != s {
+			return redacted, drop
+		}
+		return v, false
+	default:
+		return v, false
+	}
+}
+
+func (r *Redactor) redactString(s string, hits map[string]uint64) (interface{}, bool) {
+	for _, rule := range r.rules {
+		if rule.re == nil {
+			continue
+		}
+		drop := false
+		s = rule.re.ReplaceAllStringFunc(s, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			hits[rule.name]++
+			if rule.action == RedactDrop {
+				drop = true
+				return match
+			}
+			return r.redactText(match, rule.action)
+		})
+		if drop {
+			return nil, true
+		}
+	}
+	return s, false
+}
+
+func (r *Redactor) redactText(s string, action RedactAction) string {
+	if action == RedactHash {
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte(s))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:16])
+	}
+	return redactedMarker
+}
+
+// luhnValid checks the Luhn checksum of a card number, ignoring separators.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
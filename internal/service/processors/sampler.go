@@ -0,0 +1,191 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iancoleman/orderedmap"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/service"
+)
+
+const (
+	// FieldSuppressed holds, in sampling summaries, how many entries were
+	// dropped.
+	FieldSuppressed = "suppressed"
+	// FieldSampleKey holds, in sampling summaries, the key values of the
+	// dropped entries.
+	FieldSampleKey = "sample_key"
+
+	DefaultSampleSummaryInterval = 10 * time.Second
+)
+
+// DefaultSampleKeys groups entries by level and message.
+var DefaultSampleKeys = []string{FieldLevel, FieldMessage}
+
+// SamplerConfig configures a Sampler. Every and Rate may be combined: an entry
+// is kept only when both allow it.
+type SamplerConfig struct {
+	// Every keeps one entry out of each Every entries of a key (1-in-N).
+	Every int
+	// Rate keeps at most Rate entries per second of a key (token bucket with
+	// a burst of one second worth of entries).
+	Rate float64
+	// Keys are the fields identifying a stream of similar entries. Default:
+	// DefaultSampleKeys.
+	Keys []string
+	// SummaryInterval is how often suppressed entries are reported. Default:
+	// DefaultSampleSummaryInterval.
+	SummaryInterval time.Duration
+}
+
+type sampleBucket struct {
+	values     []interface{}
+	seen       uint64
+	tokens     float64
+	lastRefill time.Time
+	suppressed uint64
+}
+
+// Sampler thins out noisy streams of entries: per key, it keeps 1-in-N
+// entries and/or a maximum rate, skipping the others. Every SummaryInterval
+// it emits a synthetic entry per key saying how many entries were
+// suppressed, and once more at the end of the input.
+type Sampler struct {
+	cfg SamplerConfig
+	now func() time.Time
+
+	mu          sync.Mutex
+	buckets     map[string]*sampleBucket
+	lastSummary time.Time
+}
+
+func NewSampler(cfg SamplerConfig) (*Sampler, error) {
+	if cfg.Every < 0 || cfg.Rate < 0 {
+		return nil, fmt.Errorf("sampling every and rate must not be negative")
+	}
+	if cfg.Every == 0 && cfg.Rate == 0 {
+		return nil, fmt.Errorf("sampling needs every or rate")
+	}
+	if len(cfg.Keys) == 0 {
+		cfg.Keys = DefaultSampleKeys
+	}
+	if cfg.SummaryInterval <= 0 {
+		cfg.SummaryInterval = DefaultSampleSummaryInterval
+	}
+	return &Sampler{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: make(map[string]*sampleBucket),
+	}, nil
+}
+
+func (s *Sampler) Process(_ context.Context, entry *domain.Entry) error {
+	values := make([]interface{}, len(s.cfg.Keys))
+	for i, k := range s.cfg.Keys {
+		values[i], _ = entryValue(entry, k)
+	}
+	key := fmt.Sprint(values...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.lastSummary.IsZero() {
+		s.lastSummary = now
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &sampleBucket{
+			values:     values,
+			tokens:     s.burst(),
+			lastRefill: now,
+		}
+		s.buckets[key] = b
+	}
+
+	b.seen++
+	keep := true
+	if s.cfg.Every > 0 && (b.seen-1)%uint64(s.cfg.Every) != 0 {
+		keep = false
+	}
+	if keep && s.cfg.Rate > 0 {
+		b.tokens += now.Sub(b.lastRefill).Seconds() * s.cfg.Rate
+		if burst := s.burst(); b.tokens > burst {
+			b.tokens = burst
+		}
+		b.lastRefill = now
+		if b.tokens >= 1 {
+			b.tokens--
+		} else {
+			keep = false
+		}
+	}
+	if !keep {
+		b.suppressed++
+		return service.ErrSkipEntry
+	}
+	return nil
+}
+
+// burst is the bucket size: one second worth of entries, at least one.
+func (s *Sampler) burst() float64 {
+	return math.Max(1, s.cfg.Rate)
+}
+
+// Emit returns the summaries of the suppressed entries once per
+// SummaryInterval, or right away when final.
+func (s *Sampler) Emit(_ context.Context, final bool) []*domain.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !final && now.Sub(s.lastSummary) < s.cfg.SummaryInterval {
+		return nil
+	}
+	s.lastSummary = now
+
+	keys := make([]string, 0, len(s.buckets))
+	for key, b := range s.buckets {
+		if b.suppressed > 0 {
+			keys = append(keys, key)
+		} else if now.Sub(b.lastRefill) > s.cfg.SummaryInterval && b.tokens >= s.burst() {
+			// Idle and full: forget it, it would be recreated identical.
+			delete(s.buckets, key)
+		}
+	}
+	sort.Strings(keys)
+
+	summaries := make([]*domain.Entry, 0, len(keys))
+	for _, key := range keys {
+		b := s.buckets[key]
+		summaries = append(summaries, s.summary(now, b))
+		b.suppressed = 0
+	}
+	return summaries
+}
+
+func (s *Sampler) summary(now time.Time, b *sampleBucket) *domain.Entry {
+	sampleKey := orderedmap.New()
+	level := string(domain.LevelInfo)
+	for i, k := range s.cfg.Keys {
+		sampleKey.Set(k, b.values[i])
+		if l, ok := b.values[i].(string); ok && k == FieldLevel && l != "" {
+			level = l
+		}
+	}
+
+	entry := domain.NewEntry()
+	entry.Set(FieldTimestamp, now.Format(time.RFC3339Nano))
+	entry.Set(FieldLevel, level)
+	entry.Set("msg", fmt.Sprintf("sampling suppressed %d entries with the same %s", b.suppressed, strings.Join(s.cfg.Keys, "+")))
+	entry.Set(FieldSuppressed, float64(b.suppressed))
+	entry.Set(FieldSampleKey, *sampleKey)
+	return entry
+}
@@ -0,0 +1,127 @@
+package processors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/service"
+)
+
+func TestSampler(t *testing.T) {
+	ctx := context.Background()
+
+	newEntry := func(level, msg string) *domain.Entry {
+		entry := domain.NewEntry()
+		entry.Set("level", level)
+		entry.Set("msg", msg)
+		return entry
+	}
+	newSampler := func(t *testing.T, cfg SamplerConfig) (*Sampler, *time.Time) {
+		s, err := NewSampler(cfg)
+		require.NoError(t, err)
+		now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		s.now = func() time.Time {
+			return now
+		}
+		return s, &now
+	}
+	kept := func(t *testing.T, s *Sampler, entry *domain.Entry) bool {
+		err := s.Process(ctx, entry)
+		if err != nil {
+			require.ErrorIs(t, err, service.ErrSkipEntry)
+		}
+		return err == nil
+	}
+
+	t.Run("should keep one in N per key", func(t *testing.T) {
+		s, _ := newSampler(t, SamplerConfig{Every: 3})
+		var got []bool
+		for i := 0; i < 6; i++ {
+			got = append(got, kept(t, s, newEntry("debug", "cache miss")))
+		}
+		assert.Equal(t, []bool{true, false, false, true, false, false}, got)
+
+		// Another key has its own counter.
+		assert.True(t, kept(t, s, newEntry("info", "cache miss")))
+	})
+
+	t.Run("should limit the rate per key", func(t *testing.T) {
+		s, now := newSampler(t, SamplerConfig{Rate: 2})
+		var got []bool
+		for i := 0; i < 4; i++ {
+			got = append(got, kept(t, s, newEntry("debug", "tick")))
+		}
+		assert.Equal(t, []bool{true, true, false, false}, got)
+
+		*now = now.Add(500 * time.Millisecond)
+		assert.True(t, kept(t, s, newEntry("debug", "tick")))
+		assert.False(t, kept(t, s, newEntry("debug", "tick")))
+	})
+
+	t.Run("should allow rates below one per second", func(t *testing.T) {
+		s, now := newSampler(t, SamplerConfig{Rate: 0.5})
+		assert.True(t, kept(t, s, newEntry("debug", "tick")))
+		assert.False(t, kept(t, s, newEntry("debug", "tick")))
+		*now = now.Add(2 * time.Second)
+		assert.True(t, kept(t, s, newEntry("debug", "tick")))
+	})
+
+	t.Run("should group by the configured keys", func(t *testing.T) {
+		s, _ := newSampler(t, SamplerConfig{Every: 2, Keys: []string{"service"}})
+		e1 := newEntry("debug", "a")
+		e1.Set("service", "api")
+		e2 := newEntry("error", "b")
+		e2.Set("service", "api")
+		assert.True(t, kept(t, s, e1))
+		assert.False(t, kept(t, s, e2))
+	})
+
+	t.Run("should summarize the suppressed entries periodically", func(t *testing.T) {
+		s, now := newSampler(t, SamplerConfig{Every: 10, SummaryInterval: time.Minute})
+		for i := 0; i < 25; i++ {
+			kept(t, s, newEntry("debug", "cache miss"))
+		}
+		kept(t, s, newEntry("info", "served"))
+
+		assert.Empty(t, s.Emit(ctx, false), "too early")
+
+		*now = now.Add(time.Minute)
+		summaries := s.Emit(ctx, false)
+		require.Len(t, summaries, 1)
+		summary := summaries[0]
+		level, _ := summary.Get("level")
+		assert.Equal(t, "debug", level)
+		suppressed, _ := summary.Get(FieldSuppressed)
+		assert.Equal(t, float64(22), suppressed)
+		sampleKey, _ := summary.Get(FieldSampleKey)
+		sk := sampleKey.(orderedmap.OrderedMap)
+		msg, _ := sk.Get(FieldMessage)
+		assert.Equal(t, "cache miss", msg)
+		logEntry := mapToLogEntry(summary)
+		assert.Equal(t, *now, logEntry.Timestamp.UTC())
+		assert.Contains(t, logEntry.Message, "suppressed 22 entries")
+
+		// Counters restart after a summary.
+		assert.Empty(t, s.Emit(ctx, true))
+	})
+
+	t.Run("should summarize on the final call", func(t *testing.T) {
+		s, _ := newSampler(t, SamplerConfig{Every: 2})
+		kept(t, s, newEntry("debug", "x"))
+		kept(t, s, newEntry("debug", "x"))
+		assert.Len(t, s.Emit(ctx, true), 1)
+	})
+
+	t.Run("should reject invalid configurations", func(t *testing.T) {
+		for _, cfg := range []SamplerConfig{{}, {Every: -1}, {Rate: -1}} {
+			_, err := NewSampler(cfg)
+			assert.Error(t, err, "%+v", cfg)
+		}
+	})
+}
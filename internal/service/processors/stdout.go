@@ -3,25 +3,141 @@ package processors
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/iancoleman/orderedmap"
 
 	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/stacktrace"
+	"github.com/jamillosantos/lovr/internal/timestamp"
 )
 
+// StdoutFormat selects how Stdout renders entries.
+type StdoutFormat string
+
+const (
+	// FormatTree prints the well-known keys as a table and the remaining
+	// fields as a tree.
+	FormatTree StdoutFormat = "tree"
+	// FormatRaw prints each line exactly as read from the source.
+	FormatRaw StdoutFormat = "raw"
+	// FormatCompact prints each entry in a single line: time, level, message
+	// and the remaining fields as key=value pairs.
+	FormatCompact StdoutFormat = "compact"
+)
+
+// ParseStdoutFormat validates a format name given by the user.
+func ParseStdoutFormat(s string) (StdoutFormat, error) {
+	switch f := StdoutFormat(s); f {
+	case FormatTree, FormatRaw, FormatCompact:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", s)
+	}
+}
+
 type Stdout struct {
+	w          io.Writer
+	format     StdoutFormat
+	timeFormat string
+	fields     []string
+	width      int
+	template   *template.Template
+	foldFrames bool
+}
+
+type StdoutOption func(*Stdout)
+
+// WithFormat sets the rendering format. Default: FormatTree.
+func WithFormat(format StdoutFormat) StdoutOption {
+	return func(s *Stdout) {
+		s.format = format
+	}
+}
+
+// WithTimeFormat sets the Go time layout of FormatCompact. Default:
+// DefaultCompactTimeFormat.
+func WithTimeFormat(layout string) StdoutOption {
+	return func(s *Stdout) {
+		s.timeFormat = layout
+	}
 }
 
-func NewStdout() *Stdout {
-	return &Stdout{}
+// WithFields selects, in order, the fields FormatCompact prints after the
+// message. Names are resolved as in searches (message, trace_id, dotted
+// paths...). Default: every field but the well-known ones, as logged.
+func WithFields(fields []string) StdoutOption {
+	return func(s *Stdout) {
+		s.fields = fields
+	}
+}
+
+// WithWidth cuts the FormatCompact lines to width characters. Default: 0,
+// which does not cut them.
+func WithWidth(width int) StdoutOption {
+	return func(s *Stdout) {
+		s.width = width
+	}
+}
+
+// WithTemplate renders the entries with an output template (see
+// ParseTemplate) instead of the format.
+func WithTemplate(tmpl *template.Template) StdoutOption {
+	return func(s *Stdout) {
+		s.template = tmpl
+	}
+}
+
+// WithFoldFrames collapses the consecutive library frames of stack traces
+// (standard library, vendored packages, node_modules...) into a line
+// counting them.
+func WithFoldFrames(fold bool) StdoutOption {
+	return func(s *Stdout) {
+		s.foldFrames = fold
+	}
+}
+
+// WithWriter sets where the entries are printed. Default: os.Stdout.
+func WithWriter(w io.Writer) StdoutOption {
+	return func(s *Stdout) {
+		s.w = w
+	}
+}
+
+func NewStdout(opts ...StdoutOption) *Stdout {
+	s := &Stdout{
+		w:          os.Stdout,
+		format:     FormatTree,
+		timeFormat: DefaultCompactTimeFormat,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
 }
 
 func (s *Stdout) Process(_ context.Context, entry *domain.Entry) error {
+	switch {
+	case s.template != nil:
+		return s.printTemplate(entry)
+	case s.format == FormatRaw:
+		return s.printRaw(entry)
+	case s.format == FormatCompact:
+		return s.printCompact(entry)
+	}
+	if s.printRepeat(entry) {
+		_, _ = fmt.Fprintln(s.w, "----------------------------------------")
+		return nil
+	}
+
 	logEntry := mapToLogEntry(entry)
 	data := []domain.LogField{
 		{
@@ -35,6 +151,9 @@ func (s *Stdout) Process(_ context.Context, entry *domain.Entry) error {
 			Value: logEntry.Timestamp.Format("2006-01-02 15:04:05.999999999 Z07:00"),
 		},
 	}
+	if logEntry.TraceID != "" {
+		data = append(data, domain.LogField{Key: labelTrace, Value: formatTrace(logEntry)})
+	}
 	s.printTable("", data, withColumnWidth(10), withLabelDecorator(labelDecorator))
 
 	dataFields := toDataFields(logEntry.Fields)
@@ -52,10 +171,39 @@ func (s *Stdout) Process(_ context.Context, entry *domain.Entry) error {
 	if hasStacktrace {
 		s.printString("    ", s.formatStacktrace(logEntry.Stacktrace))
 	}
-	fmt.Println("----------------------------------------")
+	_, _ = fmt.Fprintln(s.w, "----------------------------------------")
 	return nil
 }
 
+// printRepeat prints the note standing for the entries collapsed by a
+// Deduper, reporting whether the entry is one: the entry itself was printed
+// when its run started.
+func (s *Stdout) printRepeat(entry *domain.Entry) bool {
+	count, first, last, ok := repeatOf(entry)
+	if !ok {
+		return false
+	}
+	msg, _ := entryValue(entry, FieldMessage)
+	_, _ = fmt.Fprintf(s.w, "%s %q repeated %d times from %s to %s\n", colorTree("↑"), msg, count, first, last)
+	return true
+}
+
+// printRaw prints the line as read from the source. Entries created by lovr
+// itself have none and are printed as JSON instead, as are collapsed repeats
+// (whose line was already printed).
+func (s *Stdout) printRaw(entry *domain.Entry) error {
+	raw := entry.Raw
+	if _, _, _, repeated := repeatOf(entry); raw == "" || repeated {
+		data, err := json.Marshal(entry.OrderedMap)
+		if err != nil {
+			return fmt.Errorf("error encoding the entry: %w", err)
+		}
+		raw = string(data)
+	}
+	_, err := fmt.Fprintln(s.w, raw)
+	return err
+}
+
 func toDataFields(m orderedmap.OrderedMap) []domain.LogField {
 	fieldKeys := m.Keys()
 	dataFields := make([]domain.LogField, 0, len(fieldKeys))
@@ -77,8 +225,20 @@ var (
 	labelMessage    = "Message"
 	labelCaller     = "Caller"
 	labelStacktrace = "Stacktrace"
+	labelTrace      = "Trace"
 )
 
+func formatTrace(logEntry domain.LogEntry) string {
+	trace := logEntry.TraceID
+	if logEntry.SpanID != "" {
+		trace += " span " + logEntry.SpanID
+	}
+	if logEntry.ParentSpanID != "" {
+		trace += " parent " + logEntry.ParentSpanID
+	}
+	return trace
+}
+
 type formatDecorator func(format string, args ...interface{}) string
 
 type labelAlignment string
@@ -154,12 +314,12 @@ func (s *Stdout) printTable(prefix string, table []domain.LogField, o ...formatO
 		case orderedmap.OrderedMap:
 			dataFields = toDataFields(vv)
 		default:
-			fmt.Print(d("%s%s", prefix+p, opts.LabelDecorator("%"+string(opts.labelAlignment)+strconv.Itoa(opts.ColumnWidth)+"s", f.Key)))
-			fmt.Printf(": %v\n", f.Value)
+			_, _ = fmt.Fprint(s.w, d("%s%s", prefix+p, opts.LabelDecorator("%"+string(opts.labelAlignment)+strconv.Itoa(opts.ColumnWidth)+"s", f.Key)))
+			_, _ = fmt.Fprintf(s.w, ": %v\n", f.Value)
 			continue
 		}
-		fmt.Print(d("%s%s", prefix+p, opts.LabelDecorator("%s", f.Key)))
-		fmt.Print(":\n")
+		_, _ = fmt.Fprint(s.w, d("%s%s", prefix+p, opts.LabelDecorator("%s", f.Key)))
+		_, _ = fmt.Fprint(s.w, ":\n")
 		p = colorTree("│   ")
 		if i == len(table)-1 {
 			p = "    "
@@ -173,7 +333,7 @@ func (s *Stdout) printString(prefix string, str string) {
 	scanner := bufio.NewScanner(strings.NewReader(str))
 	for scanner.Scan() {
 		line := scanner.Text()
-		fmt.Printf("%s%s\n", prefix, line)
+		_, _ = fmt.Fprintf(s.w, "%s%s\n", prefix, line)
 	}
 }
 
@@ -185,6 +345,7 @@ func withTree() formatOption {
 
 var (
 	levelMapping = map[domain.Level]formatDecorator{
+		domain.LevelTrace:   color.New(color.FgHiBlack).Sprintf,
 		domain.LevelDebug:   color.New(color.Bold, color.FgHiBlue).Sprintf,
 		domain.LevelError:   color.New(color.Bold, color.FgHiRed).Sprintf,
 		domain.LevelInfo:    color.New(color.Bold, color.FgHiCyan).Sprintf,
@@ -204,16 +365,80 @@ func (s *Stdout) formatLevel(level domain.Level) string {
 	return m(level.String())
 }
 
-func (s *Stdout) formatStacktrace(stacktrace string) string {
-	// TODO Create a method for highlighting the stack trace.
-	return stacktrace
+var (
+	colorPanic    = color.New(color.Bold, color.FgHiRed).Sprint
+	colorFunction = color.New(color.FgHiCyan).Sprint
+	colorLocation = color.New(color.FgHiGreen).Sprint
+)
+
+// formatStacktrace highlights the functions and file:line of the frames,
+// dimming (or folding) those of libraries. Stack traces that cannot be
+// parsed are kept as they are.
+func (s *Stdout) formatStacktrace(raw string) string {
+	trace, ok := stacktrace.Parse(raw)
+	if !ok {
+		return raw
+	}
+	var b strings.Builder
+	for _, block := range trace.Blocks {
+		switch {
+		case block.Header == "":
+		case block.Kind == stacktrace.KindPanic:
+			b.WriteString(colorPanic(block.Header) + "\n")
+		default:
+			b.WriteString(labelDecorator("%s", block.Header) + "\n")
+		}
+		for i := 0; i < len(block.Frames); i++ {
+			frame := block.Frames[i]
+			if s.foldFrames && frame.Library {
+				folded := 1
+				for i+1 < len(block.Frames) && block.Frames[i+1].Library {
+					folded++
+					i++
+				}
+				b.WriteString(colorTree(fmt.Sprintf("  … %d library frame%s", folded, plural(folded))) + "\n")
+				continue
+			}
+			b.WriteString("  " + formatFrame(frame) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func formatFrame(frame stacktrace.Frame) string {
+	location := frame.File
+	if frame.Line > 0 {
+		location += ":" + strconv.Itoa(frame.Line)
+	}
+	if frame.Column > 0 {
+		location += ":" + strconv.Itoa(frame.Column)
+	}
+	decorateFunction, decorateLocation, decorateCode := colorFunction, colorLocation, fmt.Sprint
+	if frame.Library {
+		decorateFunction, decorateLocation, decorateCode = colorTree, colorTree, colorTree
+	}
+	text := decorateLocation(location)
+	if frame.Function != "" {
+		text = decorateFunction(frame.Function) + " " + text
+	}
+	if frame.Code != "" {
+		text += "\n      " + decorateCode(frame.Code)
+	}
+	return text
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
 }
 
 // mapToLogEntry extracts the well-known keys (timestamp, msg, level, caller,
-// stacktrace) from a copy of inputData, leaving the remainder as Fields. The
-// input is not modified, so multiple processors can extract from the same
-// entry independently.
-func mapToLogEntry(inputData *orderedmap.OrderedMap) domain.LogEntry {
+// stacktrace, trace and span IDs) from a copy of entry, leaving the remainder
+// as Fields. The input is not modified, so multiple processors can extract
+// from the same entry independently.
+func mapToLogEntry(entry *domain.Entry) domain.LogEntry {
 	var (
 		ts         time.Time
 		msg        string
@@ -221,28 +446,35 @@ func mapToLogEntry(inputData *orderedmap.OrderedMap) domain.LogEntry {
 		caller     string
 		stacktrace string
 	)
-	data := copyOrderedMap(*inputData)
-	inputData = &data
+	data := copyOrderedMap(entry.OrderedMap)
+	inputData := &data
 	if m, key, ok := getTS(inputData); ok {
-		ts = parseTS(m)
+		ts, _ = timestamp.Parse(m)
 		inputData.Delete(key)
 	}
-	if s, key, ok := getString(inputData, "msg"); ok {
+	if s, key, ok := getString(inputData, messageKeys...); ok {
 		msg = s
 		inputData.Delete(key)
 	}
-	if s, key, ok := getString(inputData, "level"); ok {
-		level = domain.Level(s)
+	if v, key, ok := getValue(inputData, levelKeys...); ok {
+		level, _ = domain.NormalizeLevel(v)
 		inputData.Delete(key)
+		// Keep the value as logged when normalization changed it (WARN, 40...).
+		if raw := fmt.Sprint(v); raw != string(level) {
+			inputData.Set(FieldLevelRaw, raw)
+		}
 	}
-	if s, key, ok := getString(inputData, "caller"); ok {
+	if s, key, ok := getString(inputData, FieldCaller); ok {
 		caller = s
 		inputData.Delete(key)
 	}
-	if s, key, ok := getString(inputData, "stacktrace"); ok {
+	if s, key, ok := getString(inputData, FieldStacktrace); ok {
 		stacktrace = s
 		inputData.Delete(key)
 	}
+	traceID := extractID(inputData, traceIDKeys)
+	spanID := extractID(inputData, spanIDKeys)
+	parentSpanID := extractID(inputData, parentSpanIDKeys)
 
 	return domain.LogEntry{
 		Timestamp:  ts,
@@ -251,47 +483,89 @@ func mapToLogEntry(inputData *orderedmap.OrderedMap) domain.LogEntry {
 		Fields:     *inputData,
 		Caller:     caller,
 		Stacktrace: stacktrace,
-	}
+		Raw:        entry.Raw,
 
+		TraceID:      traceID,
+		SpanID:       spanID,
+		ParentSpanID: parentSpanID,
+	}
 }
 
-func copyOrderedMap(m orderedmap.OrderedMap) orderedmap.OrderedMap {
+func deepCopyOrderedMap(m orderedmap.OrderedMap) orderedmap.OrderedMap {
 	cp := orderedmap.New()
 	for _, k := range m.Keys() {
 		v, _ := m.Get(k)
+		if nested, ok := v.(orderedmap.OrderedMap); ok {
+			v = deepCopyOrderedMap(nested)
+		}
 		cp.Set(k, v)
 	}
 	return *cp
 }
 
-func parseTS(m interface{}) time.Time {
-	switch m := m.(type) {
-	case string:
-		return parseTSString(m)
-	case float64:
-		seconds := int64(m) // throw away the
-		nseconds := int64((m - float64(seconds)) * float64(time.Second))
-		return time.Unix(seconds, nseconds)
-	default:
-		return time.Time{}
+func copyOrderedMap(m orderedmap.OrderedMap) orderedmap.OrderedMap {
+	cp := orderedmap.New()
+	for _, k := range m.Keys() {
+		v, _ := m.Get(k)
+		cp.Set(k, v)
 	}
+	return *cp
 }
 
-var tsFormats = []string{time.Layout, time.ANSIC, time.UnixDate, time.RubyDate, time.RFC822, time.RFC822Z, time.RFC850,
-	time.RFC1123, time.RFC1123Z, time.RFC3339, time.RFC3339Nano, time.Stamp, time.StampMilli, time.StampMicro,
-	time.StampNano}
+var timestampKeys = []string{"timestamp", "@timestamp", "ts", "time", "date", "datetime"}
 
-func parseTSString(m string) time.Time {
-	for _, f := range tsFormats {
-		if t, err := time.Parse(f, m); err == nil {
-			return t
+var levelKeys = []string{"level", "lvl", "severity"}
+
+var messageKeys = []string{"msg", FieldMessage}
+
+// The spellings of the trace IDs, as top-level keys or dotted paths.
+var (
+	traceIDKeys      = []string{"trace_id", "traceId", "traceID", "traceid", "trace.id", "dd.trace_id", "otel.trace_id"}
+	spanIDKeys       = []string{"span_id", "spanId", "spanID", "spanid", "span.id", "dd.span_id", "otel.span_id"}
+	parentSpanIDKeys = []string{"parent_span_id", "parentSpanId", "parentSpanID", "dd.parent_id", "otel.parent_span_id"}
+)
+
+// extractID removes the first of keys found in data with a string value,
+// returning it. Numeric IDs, as some Datadog tracers log them, are left as
+// fields: decoded as float64, 64-bit IDs lose their last digits, and would
+// link unrelated entries.
+func extractID(data *orderedmap.OrderedMap, keys []string) string {
+	for _, k := range keys {
+		v, ok := lookupPath(data, k)
+		if !ok {
+			continue
+		}
+		id, ok := v.(string)
+		if !ok || id == "" {
+			continue
+		}
+		if _, ok := data.Get(k); ok {
+			data.Delete(k)
+			return id
 		}
+		// The nested objects are shared with the entry: copy the ones on the
+		// path before deleting from them, and drop those left empty.
+		var parents []string
+		for _, top := range data.Keys() {
+			if nested, ok := data.Get(top); ok && strings.HasPrefix(k, top+".") {
+				if nestedMap, ok := nested.(orderedmap.OrderedMap); ok {
+					data.Set(top, deepCopyOrderedMap(nestedMap))
+					parents = append(parents, top)
+				}
+			}
+		}
+		deletePath(data, k)
+		for _, top := range parents {
+			nested, _ := data.Get(top)
+			if nestedMap, ok := nested.(orderedmap.OrderedMap); ok && len(nestedMap.Keys()) == 0 {
+				data.Delete(top)
+			}
+		}
+		return id
 	}
-	return time.Time{}
+	return ""
 }
 
-var timestampKeys = []string{"timestamp", "@timestamp", "ts", "time", "date", "datetime"}
-
 func getTS(data *orderedmap.OrderedMap) (interface{}, string, bool) {
 	for _, k := range timestampKeys {
 		if v, ok := data.Get(k); ok {
@@ -301,6 +575,15 @@ func getTS(data *orderedmap.OrderedMap) (interface{}, string, bool) {
 	return nil, "", false
 }
 
+func getValue(m *orderedmap.OrderedMap, keys ...string) (interface{}, string, bool) {
+	for _, k := range keys {
+		if v, ok := m.Get(k); ok {
+			return v, k, true
+		}
+	}
+	return nil, "", false
+}
+
 func getString(m *orderedmap.OrderedMap, s ...string) (string, string, bool) {
 	for _, k := range s {
 		if m, ok := m.Get(k); ok {
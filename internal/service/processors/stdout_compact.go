@@ -0,0 +1,188 @@
+package processors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iancoleman/orderedmap"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+// DefaultCompactTimeFormat is the time layout of FormatCompact.
+const DefaultCompactTimeFormat = "15:04:05.000"
+
+// compactLevels are the labels of the canonical levels in FormatCompact, all
+// as wide as the widest one.
+var compactLevels = map[domain.Level]string{
+	domain.LevelTrace:   "TRACE",
+	domain.LevelDebug:   "DEBUG",
+	domain.LevelInfo:    "INFO",
+	domain.LevelWarning: "WARN",
+	domain.LevelError:   "ERROR",
+	domain.LevelFatal:   "FATAL",
+	domain.LevelPanic:   "PANIC",
+}
+
+// compactSegment is a piece of a compact line printed with a color.
+type compactSegment struct {
+	text     string
+	decorate formatDecorator
+}
+
+// printCompact prints the entry in a single line: time, level, message and
+// the extra fields as key=value pairs.
+func (s *Stdout) printCompact(entry *domain.Entry) error {
+	var segments []compactSegment
+	if count, first, last, ok := repeatOf(entry); ok {
+		// The entry itself was printed when its run started.
+		msg, _ := entryValue(entry, FieldMessage)
+		segments = append(segments,
+			compactSegment{text: "↑", decorate: colorTreef},
+			compactSegment{text: fmt.Sprintf(" %q repeated %d times from %s to %s", msg, count, first, last)},
+		)
+		return s.writeCompact(segments)
+	}
+
+	logEntry := mapToLogEntry(entry)
+	if !logEntry.Timestamp.IsZero() {
+		segments = append(segments, compactSegment{
+			text:     logEntry.Timestamp.Format(s.timeFormat) + " ",
+			decorate: colorTreef,
+		})
+	}
+	label, ok := compactLevels[logEntry.Level]
+	if !ok {
+		label = strings.ToUpper(string(logEntry.Level))
+	}
+	segments = append(segments, compactSegment{
+		text:     fmt.Sprintf("%-5s", label),
+		decorate: levelMapping[logEntry.Level],
+	})
+	segments = append(segments, compactSegment{text: " " + escapeNewlines(logEntry.Message)})
+
+	var pairs []domain.LogField
+	if s.fields != nil {
+		for _, field := range s.fields {
+			if v, ok := entryValue(entry, field); ok {
+				pairs = append(pairs, domain.LogField{Key: field, Value: v})
+			}
+		}
+	} else {
+		pairs = compactPairs("", logEntry.Fields, pairs)
+	}
+	for _, pair := range pairs {
+		segments = append(segments,
+			compactSegment{text: " " + pair.Key + "=", decorate: colorTreef},
+			compactSegment{text: formatCompactValue(pair.Value)},
+		)
+	}
+	return s.writeCompact(segments)
+}
+
+// writeCompact prints the segments as a line, cut to the width when set.
+func (s *Stdout) writeCompact(segments []compactSegment) error {
+	if s.width > 0 {
+		segments = truncateSegments(segments, s.width)
+	}
+	var b strings.Builder
+	for _, segment := range segments {
+		if segment.decorate == nil || segment.text == "" {
+			b.WriteString(segment.text)
+			continue
+		}
+		b.WriteString(segment.decorate("%s", segment.text))
+	}
+	_, err := fmt.Fprintln(s.w, b.String())
+	return err
+}
+
+// truncateSegments cuts the segments to width characters, ending them with
+// an ellipsis when anything was left out.
+func truncateSegments(segments []compactSegment, width int) []compactSegment {
+	total := 0
+	for _, segment := range segments {
+		total += utf8.RuneCountInString(segment.text)
+	}
+	if total <= width {
+		return segments
+	}
+	left := width - 1
+	truncated := make([]compactSegment, 0, len(segments))
+	for _, segment := range segments {
+		n := utf8.RuneCountInString(segment.text)
+		if n <= left {
+			truncated = append(truncated, segment)
+			left -= n
+			continue
+		}
+		runes := []rune(segment.text)
+		segment.text = string(runes[:left])
+		truncated = append(truncated, segment)
+		break
+	}
+	return append(truncated, compactSegment{text: "…", decorate: colorTreef})
+}
+
+// compactPairs appends the fields of m to pairs, nested objects as dotted
+// keys.
+func compactPairs(prefix string, m orderedmap.OrderedMap, pairs []domain.LogField) []domain.LogField {
+	for _, k := range m.Keys() {
+		v, _ := m.Get(k)
+		if nested, ok := v.(orderedmap.OrderedMap); ok {
+			pairs = compactPairs(prefix+k+".", nested, pairs)
+			continue
+		}
+		pairs = append(pairs, domain.LogField{Key: prefix + k, Value: v})
+	}
+	return pairs
+}
+
+// formatCompactValue renders a field value in logfmt style: strings are
+// quoted when they are empty or hold spaces, quotes, equal signs or control
+// characters; arrays and objects are printed as JSON.
+func formatCompactValue(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if needsQuoting(vv) {
+			return strconv.Quote(vv)
+		}
+		return vv
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(vv)
+	default:
+		data, err := json.Marshal(vv)
+		if err != nil {
+			return fmt.Sprint(vv)
+		}
+		return string(data)
+	}
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeNewlines keeps multiline messages in a single line.
+func escapeNewlines(s string) string {
+	return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(s)
+}
+
+func colorTreef(format string, args ...interface{}) string {
+	return colorTree(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,154 @@
+package processors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+// TemplateData is what output templates render: the well-known keys of an
+// entry and the remaining fields, nested objects as dotted keys.
+type TemplateData struct {
+	// Time is zero for entries without a timestamp.
+	Time         time.Time
+	Level        domain.Level
+	Message      string
+	Caller       string
+	Stacktrace   string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Fields       []domain.LogField
+	// Raw is the line as read from the source.
+	Raw string
+
+	entry *domain.Entry
+}
+
+// Field returns the value of a field, resolved as in searches (message,
+// trace_id, dotted paths...), or an empty string when the entry does not
+// have it.
+func (d TemplateData) Field(path string) interface{} {
+	v, ok := entryValue(d.entry, path)
+	if !ok {
+		return ""
+	}
+	return v
+}
+
+var templateColors = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+	"gray":    color.FgHiBlack,
+	"bold":    color.Bold,
+}
+
+// templateFuncs are the helpers available to output templates. Values come
+// last, so they can be piped: {{.Message | pad 40 | color "cyan"}}.
+var templateFuncs = template.FuncMap{
+	"color": func(name string, v interface{}) (string, error) {
+		attr, ok := templateColors[name]
+		if !ok {
+			return "", fmt.Errorf("unknown color %q", name)
+		}
+		return color.New(attr).Sprint(v), nil
+	},
+	"levelColor": func(level domain.Level, v interface{}) string {
+		decorate, ok := levelMapping[level]
+		if !ok {
+			return fmt.Sprint(v)
+		}
+		return decorate("%v", v)
+	},
+	"formatTime": func(layout string, t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	},
+	"pad": func(width int, v interface{}) string {
+		s := fmt.Sprint(v)
+		return s + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s)))
+	},
+	"padLeft": func(width int, v interface{}) string {
+		s := fmt.Sprint(v)
+		return strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s))) + s
+	},
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"value": formatCompactValue,
+	"upper": func(v interface{}) string {
+		return strings.ToUpper(fmt.Sprint(v))
+	},
+	"lower": func(v interface{}) string {
+		return strings.ToLower(fmt.Sprint(v))
+	},
+}
+
+// ParseTemplate parses an output template (Go text/template syntax). Each
+// entry is rendered in its own line.
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// LoadTemplate parses the output template in a file. The newline ending the
+// file is not part of the template.
+func LoadTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the output template: %w", err)
+	}
+	return ParseTemplate(strings.TrimSuffix(string(data), "\n"))
+}
+
+// printTemplate renders the entry with the output template. Entries the
+// template fails on (an unknown color, a field of the wrong type...) are
+// printed as the error instead, so it shows up where the entry would. The
+// entries collapsed by a Deduper are printed as a note, as in the tree
+// format.
+func (s *Stdout) printTemplate(entry *domain.Entry) error {
+	if s.printRepeat(entry) {
+		return nil
+	}
+	logEntry := mapToLogEntry(entry)
+	data := TemplateData{
+		Time:         logEntry.Timestamp,
+		Level:        logEntry.Level,
+		Message:      logEntry.Message,
+		Caller:       logEntry.Caller,
+		Stacktrace:   logEntry.Stacktrace,
+		TraceID:      logEntry.TraceID,
+		SpanID:       logEntry.SpanID,
+		ParentSpanID: logEntry.ParentSpanID,
+		Fields:       compactPairs("", logEntry.Fields, nil),
+		Raw:          logEntry.Raw,
+		entry:        entry,
+	}
+	var b strings.Builder
+	if err := s.template.Execute(&b, data); err != nil {
+		_, err = fmt.Fprintln(s.w, color.New(color.FgRed).Sprintf("### ERROR rendering the output template: %s", err))
+		return err
+	}
+	_, err := fmt.Fprintln(s.w, b.String())
+	return err
+}
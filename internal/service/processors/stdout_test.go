@@ -1,15 +1,22 @@
 package processors
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"testing"
 
+	"github.com/fatih/color"
 	"github.com/iancoleman/orderedmap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
 )
 
 func Test_mapToLogEntry(t *testing.T) {
-	newInput := func() *orderedmap.OrderedMap {
-		m := orderedmap.New()
+	newInput := func() *domain.Entry {
+		m := domain.NewEntry()
 		m.Set("ts", "2026-01-01T12:00:00Z")
 		m.Set("level", "error")
 		m.Set("msg", "hello")
@@ -25,6 +32,26 @@ func Test_mapToLogEntry(t *testing.T) {
 		assert.Equal(t, []string{"field1"}, got.Fields.Keys())
 	})
 
+	t.Run("should normalize the level keeping the logged value", func(t *testing.T) {
+		m := newInput()
+		m.Set("level", "WARN")
+		got := mapToLogEntry(m)
+		assert.Equal(t, "warning", string(got.Level))
+		raw, ok := got.Fields.Get(FieldLevelRaw)
+		assert.True(t, ok)
+		assert.Equal(t, "WARN", raw)
+	})
+
+	t.Run("should normalize numeric levels", func(t *testing.T) {
+		m := newInput()
+		m.Delete("level")
+		m.Set("severity", float64(50))
+		got := mapToLogEntry(m)
+		assert.Equal(t, "error", string(got.Level))
+		raw, _ := got.Fields.Get(FieldLevelRaw)
+		assert.Equal(t, "50", raw)
+	})
+
 	t.Run("should not mutate the input entry", func(t *testing.T) {
 		m := newInput()
 		got1 := mapToLogEntry(m)
@@ -32,4 +59,301 @@ func Test_mapToLogEntry(t *testing.T) {
 		assert.Equal(t, got1, got2)
 		assert.Equal(t, []string{"ts", "level", "msg", "field1"}, m.Keys())
 	})
+
+	t.Run("should extract the trace IDs in their various spellings", func(t *testing.T) {
+		m := newInput()
+		m.Set("traceId", "4bf92f3577b34da6")
+		m.Set("spanId", "00f067aa0ba902b7")
+		m.Set("parentSpanId", "a3ce929d0e0e4736")
+		m.Set("parent_id", "order-1")
+		got := mapToLogEntry(m)
+		assert.Equal(t, "4bf92f3577b34da6", got.TraceID)
+		assert.Equal(t, "00f067aa0ba902b7", got.SpanID)
+		assert.Equal(t, "a3ce929d0e0e4736", got.ParentSpanID)
+		assert.Equal(t, []string{"field1", "parent_id"}, got.Fields.Keys())
+	})
+
+	t.Run("should extract nested trace IDs without mutating the input", func(t *testing.T) {
+		m := newInput()
+		dd := orderedmap.New()
+		dd.Set("trace_id", "1234567890123")
+		dd.Set("span_id", "42")
+		dd.Set("env", "prod")
+		m.Set("dd", *dd)
+
+		got := mapToLogEntry(m)
+		assert.Equal(t, "1234567890123", got.TraceID)
+		assert.Equal(t, "42", got.SpanID)
+		nested, ok := got.Fields.Get("dd")
+		require.True(t, ok)
+		nestedMap := nested.(orderedmap.OrderedMap)
+		assert.Equal(t, []string{"env"}, nestedMap.Keys())
+		assert.Equal(t, []string{"trace_id", "span_id", "env"}, dd.Keys())
+	})
+
+	t.Run("should keep numeric trace IDs as fields", func(t *testing.T) {
+		m := newInput()
+		dd := orderedmap.New()
+		// 2^64-1 decodes as 18446744073709551616.
+		dd.Set("trace_id", float64(18446744073709551615))
+		m.Set("dd", *dd)
+
+		got := mapToLogEntry(m)
+		assert.Empty(t, got.TraceID)
+		nested, ok := got.Fields.Get("dd")
+		require.True(t, ok)
+		nestedMap := nested.(orderedmap.OrderedMap)
+		assert.Equal(t, []string{"trace_id"}, nestedMap.Keys())
+
+		_, ok = entryValue(m, FieldTraceID)
+		assert.False(t, ok)
+	})
+
+	t.Run("should drop nested objects left empty", func(t *testing.T) {
+		m := newInput()
+		otel := orderedmap.New()
+		otel.Set("trace_id", "abc")
+		m.Set("otel", *otel)
+
+		got := mapToLogEntry(m)
+		assert.Equal(t, "abc", got.TraceID)
+		assert.Equal(t, []string{"field1"}, got.Fields.Keys())
+	})
+}
+
+func TestStdout_Process(t *testing.T) {
+	t.Run("should print the raw line", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithFormat(FormatRaw), WithWriter(&buf))
+		entry := domain.NewEntry()
+		entry.Set("level", "info")
+		entry.Raw = `{"level":"info"}`
+		require.NoError(t, s.Process(context.Background(), entry))
+		assert.Equal(t, "{\"level\":\"info\"}\n", buf.String())
+	})
+
+	t.Run("should print entries without a raw line as JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithFormat(FormatRaw), WithWriter(&buf))
+		entry := domain.NewEntry()
+		entry.Set("level", "info")
+		entry.Set("msg", "hello")
+		require.NoError(t, s.Process(context.Background(), entry))
+		assert.Equal(t, "{\"level\":\"info\",\"msg\":\"hello\"}\n", buf.String())
+	})
+
+	t.Run("should print the tree format by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithWriter(&buf))
+		entry := domain.NewEntry()
+		entry.Set("level", "info")
+		entry.Set("msg", "hello")
+		entry.Set("field1", "value1")
+		require.NoError(t, s.Process(context.Background(), entry))
+		assert.Contains(t, buf.String(), "hello")
+		assert.Contains(t, buf.String(), "field1")
+	})
+
+	t.Run("should print the trace IDs in the tree format", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithWriter(&buf))
+		entry := domain.NewEntry()
+		entry.Set("msg", "hello")
+		entry.Set("traceId", "abc")
+		entry.Set("spanId", "s1")
+		require.NoError(t, s.Process(context.Background(), entry))
+		assert.Contains(t, buf.String(), "abc span s1")
+	})
+
+	t.Run("should print logged repeat_count fields as data", func(t *testing.T) {
+		for _, format := range []StdoutFormat{FormatTree, FormatCompact, FormatRaw} {
+			var buf bytes.Buffer
+			s := NewStdout(WithFormat(format), WithWriter(&buf))
+			entry := domain.NewEntry()
+			entry.Set("msg", "retry budget")
+			entry.Set(FieldRepeatCount, float64(3))
+			entry.Raw = `{"msg":"retry budget","repeat_count":3}`
+			require.NoError(t, s.Process(context.Background(), entry))
+			assert.NotContains(t, buf.String(), "repeated", format)
+			assert.Contains(t, buf.String(), "retry budget", format)
+		}
+	})
+}
+
+func TestStdout_formatStacktrace(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	const trace = "panic: boom\n\ngoroutine 1 [running]:\n" +
+		"main.handle(...)\n\t/app/main.go:42\n" +
+		"net/http.HandlerFunc.ServeHTTP(0xc000012345)\n\t/usr/local/go/src/net/http/server.go:2136 +0x29\n" +
+		"net/http.serverHandler.ServeHTTP({0xc0001})\n\t/usr/local/go/src/net/http/server.go:2938 +0x8e\n" +
+		"main.main()\n\t/app/main.go:10 +0x65\n"
+
+	t.Run("should print the frames one per line", func(t *testing.T) {
+		s := NewStdout()
+		assert.Equal(t, "panic: boom\ngoroutine 1 [running]:\n"+
+			"  main.handle /app/main.go:42\n"+
+			"  net/http.HandlerFunc.ServeHTTP /usr/local/go/src/net/http/server.go:2136\n"+
+			"  net/http.serverHandler.ServeHTTP /usr/local/go/src/net/http/server.go:2938\n"+
+			"  main.main /app/main.go:10\n", s.formatStacktrace(trace))
+	})
+
+	t.Run("should fold the library frames", func(t *testing.T) {
+		s := NewStdout(WithFoldFrames(true))
+		assert.Equal(t, "panic: boom\ngoroutine 1 [running]:\n"+
+			"  main.handle /app/main.go:42\n"+
+			"  … 2 library frames\n"+
+			"  main.main /app/main.go:10\n", s.formatStacktrace(trace))
+	})
+
+	t.Run("should keep stack traces that cannot be parsed", func(t *testing.T) {
+		s := NewStdout()
+		assert.Equal(t, "something\nwent wrong", s.formatStacktrace("something\nwent wrong"))
+	})
+}
+
+func TestStdout_Process_compact(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	newEntry := func() *domain.Entry {
+		entry := domain.NewEntry()
+		entry.Set("ts", "2024-03-01T10:20:30.123Z")
+		entry.Set("level", "warn")
+		entry.Set("msg", "slow request")
+		entry.Set("service", "api")
+		entry.Set("path", "/users list")
+		http := orderedmap.New()
+		http.Set("status", float64(200))
+		entry.Set("http", *http)
+		entry.Set("traceId", "abc")
+		return entry
+	}
+
+	t.Run("should print the entry in a single line", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithFormat(FormatCompact), WithWriter(&buf))
+		require.NoError(t, s.Process(context.Background(), newEntry()))
+		assert.Equal(t, "10:20:30.123 WARN  slow request service=api path=\"/users list\" http.status=200 level_raw=warn\n", buf.String())
+	})
+
+	t.Run("should print the chosen fields in order", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithFormat(FormatCompact), WithWriter(&buf), WithFields([]string{"trace_id", "http.status", "missing"}))
+		require.NoError(t, s.Process(context.Background(), newEntry()))
+		assert.Equal(t, "10:20:30.123 WARN  slow request trace_id=abc http.status=200\n", buf.String())
+	})
+
+	t.Run("should use the time format", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithFormat(FormatCompact), WithWriter(&buf), WithTimeFormat("2006-01-02"), WithFields([]string{}))
+		require.NoError(t, s.Process(context.Background(), newEntry()))
+		assert.Equal(t, "2024-03-01 WARN  slow request\n", buf.String())
+	})
+
+	t.Run("should cut the lines to the width", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithFormat(FormatCompact), WithWriter(&buf), WithWidth(24))
+		require.NoError(t, s.Process(context.Background(), newEntry()))
+		assert.Equal(t, "10:20:30.123 WARN  slow…\n", buf.String())
+	})
+
+	t.Run("should keep multiline messages in a single line", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewStdout(WithFormat(FormatCompact), WithWriter(&buf))
+		entry := domain.NewEntry()
+		entry.Set("level", "error")
+		entry.Set("msg", "failed\nretrying")
+		require.NoError(t, s.Process(context.Background(), entry))
+		assert.Equal(t, "ERROR failed\\nretrying\n", buf.String())
+	})
+}
+
+func TestStdout_Process_template(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	newEntry := func() *domain.Entry {
+		entry := domain.NewEntry()
+		entry.Set("ts", "2024-03-01T10:20:30.123Z")
+		entry.Set("level", "warn")
+		entry.Set("msg", "slow request")
+		http := orderedmap.New()
+		http.Set("status", float64(200))
+		entry.Set("http", *http)
+		entry.Set("tags", []interface{}{"a", "b"})
+		return entry
+	}
+	render := func(t *testing.T, text string) string {
+		tmpl, err := ParseTemplate(text)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		s := NewStdout(WithWriter(&buf), WithTemplate(tmpl))
+		require.NoError(t, s.Process(context.Background(), newEntry()))
+		return buf.String()
+	}
+
+	t.Run("should render the well-known keys", func(t *testing.T) {
+		out := render(t, `{{formatTime "15:04:05" .Time}} [{{.Level | upper | pad 7 | levelColor .Level}}] {{.Message}}`)
+		assert.Equal(t, "10:20:30 [WARNING] slow request\n", out)
+	})
+
+	t.Run("should look fields up by dotted path", func(t *testing.T) {
+		out := render(t, `{{.Field "http.status"}} {{.Field "message"}} ({{.Field "missing"}})`)
+		assert.Equal(t, "200 slow request ()\n", out)
+	})
+
+	t.Run("should range over the remaining fields", func(t *testing.T) {
+		out := render(t, `{{range .Fields}}{{.Key}}={{value .Value}} {{end}}`)
+		assert.Equal(t, "http.status=200 tags=[\"a\",\"b\"] level_raw=warn \n", out)
+	})
+
+	t.Run("should encode JSON and pad values", func(t *testing.T) {
+		out := render(t, `{{json (.Field "tags")}}|{{padLeft 5 (.Field "http.status")}}|{{color "red" "x"}}`)
+		assert.Equal(t, "[\"a\",\"b\"]|  200|x\n", out)
+	})
+
+	t.Run("should print the errors in place of the entries", func(t *testing.T) {
+		out := render(t, `{{color "pink" .Message}}`)
+		assert.Contains(t, out, "### ERROR rendering the output template:")
+		assert.Contains(t, out, `unknown color "pink"`)
+	})
+
+	t.Run("should print a note for the collapsed repeats", func(t *testing.T) {
+		tmpl, err := ParseTemplate(`> {{.Message}}`)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		s := NewStdout(WithWriter(&buf), WithTemplate(tmpl))
+		d := NewDeduper(DedupeConfig{})
+		for i := 0; i < 2; i++ {
+			entry := newEntry()
+			entry.Set("ts", fmt.Sprintf("2024-03-01T10:20:3%dZ", i))
+			if err := d.Process(context.Background(), entry); err == nil {
+				require.NoError(t, s.Process(context.Background(), entry))
+			}
+		}
+		for _, entry := range d.Emit(context.Background(), true) {
+			require.NoError(t, s.Process(context.Background(), entry))
+		}
+		assert.Equal(t, "> slow request\n"+
+			"↑ \"slow request\" repeated 2 times from 2024-03-01T10:20:30Z to 2024-03-01T10:20:31Z\n", buf.String())
+	})
+
+	t.Run("should fail on invalid templates", func(t *testing.T) {
+		_, err := ParseTemplate(`{{.Message`)
+		assert.ErrorContains(t, err, "invalid output template")
+	})
+}
+
+func TestParseStdoutFormat(t *testing.T) {
+	f, err := ParseStdoutFormat("raw")
+	require.NoError(t, err)
+	assert.Equal(t, FormatRaw, f)
+
+	_, err = ParseStdoutFormat("fancy")
+	assert.Error(t, err)
 }
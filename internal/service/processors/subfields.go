@@ -0,0 +1,98 @@
+package processors
+
+import (
+	"strconv"
+
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/document"
+	"github.com/blevesearch/bleve/v2/mapping"
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+// IsSubField reports whether the field is an exact or quantity sub-field,
+// indexed for searching but not part of the entries.
+func IsSubField(field string) bool {
+	return IsExactField(field) || IsQuantityField(field)
+}
+
+// subFieldMapping adds the exact and quantity sub-fields to the documents
+// mapped by the embedded mapping. Dynamic mappings cannot express them:
+// bleve maps each value once, and dynamic fields are all stored, while the
+// sub-fields are only indexed.
+//
+// It also indexes numbers, booleans and strings mapped as datetimes as text,
+// under their own field: a field logged as a number by one service and as a
+// string by another (see FieldTypes) is then searched by words whatever its
+// type (status:50*, created:2025*), as numeric strings are compared as
+// numbers in its quantity sub-field.
+//
+// The text copy is added to every number and boolean, not only to the fields
+// FieldTypes reports with several types: the types are only known as entries
+// come, so the documents indexed before a field turned mixed would lack it,
+// and the live filter (entryreader.Matcher) matches numbers as text whatever
+// their field. The copy costs one term per value, indexed but not stored.
+type subFieldMapping struct {
+	*mapping.IndexMappingImpl
+}
+
+func (m subFieldMapping) MapDocument(doc *document.Document, data interface{}) error {
+	if err := m.IndexMappingImpl.MapDocument(doc, data); err != nil {
+		return err
+	}
+	fields, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for name, v := range fields {
+		if name == FieldRaw || name == FieldTimestampNanos {
+			continue
+		}
+		m.addSubFields(doc, name, v)
+	}
+	return nil
+}
+
+// addSubFields indexes the sub-fields of the values found in v. Walking the
+// values rather than the mapped fields also covers strings mapped as
+// datetimes.
+func (m subFieldMapping) addSubFields(doc *document.Document, path string, v interface{}) {
+	keywordAnalyzer := m.AnalyzerNamed(keyword.Name)
+	walkValues(path, v, nil, func(path string, v interface{}, arrayPositions []uint64) {
+		switch vv := v.(type) {
+		case string:
+			addExactField(doc, keywordAnalyzer, path, vv, arrayPositions)
+			addQuantityField(doc, path, vv, arrayPositions)
+			if isDate(m, vv) {
+				m.addTextField(doc, path, vv, arrayPositions)
+			}
+		case float64:
+			m.addTextField(doc, path, strconv.FormatFloat(vv, 'f', -1, 64), arrayPositions)
+		case bool:
+			m.addTextField(doc, path, strconv.FormatBool(vv), arrayPositions)
+		}
+	})
+}
+
+func (m subFieldMapping) addTextField(doc *document.Document, path, value string, arrayPositions []uint64) {
+	analyzer := m.AnalyzerNamed(m.AnalyzerNameForPath(path))
+	options := index.IndexField | index.IncludeTermVectors
+	doc.AddField(document.NewTextFieldCustom(path, arrayPositions, []byte(value), options, analyzer))
+}
+
+// walkValues calls fn with the scalar values found in v, walking nested
+// objects and arrays the way bleve names and positions their fields.
+func walkValues(path string, v interface{}, arrayPositions []uint64, fn func(path string, v interface{}, arrayPositions []uint64)) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, child := range vv {
+			walkValues(path+"."+k, child, arrayPositions, fn)
+		}
+	case []interface{}:
+		for i, child := range vv {
+			positions := append(arrayPositions[:len(arrayPositions):len(arrayPositions)], uint64(i))
+			walkValues(path, child, positions, fn)
+		}
+	default:
+		fn(path, v, arrayPositions)
+	}
+}
@@ -0,0 +1,328 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/orderedmap"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+// TransformOp is the kind of a TransformStep.
+type TransformOp string
+
+const (
+	// TransformRename moves From to To.
+	TransformRename TransformOp = "rename"
+	// TransformCopy copies From to To.
+	TransformCopy TransformOp = "copy"
+	// TransformDrop removes Fields.
+	TransformDrop TransformOp = "drop"
+	// TransformKeep removes everything but Fields and the well-known keys
+	// (timestamp, message, level, caller, stacktrace and trace IDs).
+	TransformKeep TransformOp = "keep"
+	// TransformSet sets Field to Value.
+	TransformSet TransformOp = "set"
+	// TransformCast converts Field to Type: string, number, int or bool.
+	// Values that cannot be converted are left as they are.
+	TransformCast TransformOp = "cast"
+	// TransformFlatten replaces the nested objects under Field (or every
+	// nested object, when Field is empty) with top-level keys joined by
+	// Separator (default ".").
+	TransformFlatten TransformOp = "flatten"
+)
+
+// TransformStep is one operation of a field transformation. Fields are
+// addressed by dotted paths (nested.key).
+type TransformStep struct {
+	Op        TransformOp `json:"op"`
+	Field     string      `json:"field,omitempty"`
+	Fields    []string    `json:"fields,omitempty"`
+	From      string      `json:"from,omitempty"`
+	To        string      `json:"to,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+	Type      string      `json:"type,omitempty"`
+	Separator string      `json:"separator,omitempty"`
+	// When restricts the step to entries matching a query in the search
+	// syntax.
+	When string `json:"when,omitempty"`
+}
+
+// LoadTransformSteps reads a JSON array of TransformStep from a file.
+func LoadTransformSteps(path string) ([]TransformStep, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading transformations: %w", err)
+	}
+	var steps []TransformStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("invalid transformations in %s: %w", path, err)
+	}
+	return steps, nil
+}
+
+// MatcherFactory builds an EntryMatcher out of a query in the search syntax
+// (entryreader.NewMatcher, which this package cannot import).
+type MatcherFactory func(expr string) (EntryMatcher, error)
+
+type transformStep struct {
+	TransformStep
+	when EntryMatcher
+}
+
+// Transformer rewrites entries so the same concept gets the same name and
+// type across services (user_id vs userId, status as string vs number). Steps
+// run in order; later steps see the changes of the previous ones.
+type Transformer struct {
+	steps []transformStep
+}
+
+func NewTransformer(steps []TransformStep, newMatcher MatcherFactory) (*Transformer, error) {
+	t := &Transformer{
+		steps: make([]transformStep, 0, len(steps)),
+	}
+	for i, step := range steps {
+		if err := validateTransformStep(step); err != nil {
+			_ = t.Close()
+			return nil, fmt.Errorf("invalid transformation #%d (%s): %w", i+1, step.Op, err)
+		}
+		compiled := transformStep{TransformStep: step}
+		if step.When != "" {
+			m, err := newMatcher(step.When)
+			if err != nil {
+				_ = t.Close()
+				return nil, fmt.Errorf("invalid transformation #%d (%s) condition: %w", i+1, step.Op, err)
+			}
+			compiled.when = m
+		}
+		t.steps = append(t.steps, compiled)
+	}
+	return t, nil
+}
+
+func validateTransformStep(step TransformStep) error {
+	switch step.Op {
+	case TransformRename, TransformCopy:
+		if step.From == "" || step.To == "" {
+			return fmt.Errorf("from and to are required")
+		}
+	case TransformDrop, TransformKeep:
+		if len(step.Fields) == 0 {
+			return fmt.Errorf("fields is required")
+		}
+	case TransformSet:
+		if step.Field == "" {
+			return fmt.Errorf("field is required")
+		}
+	case TransformCast:
+		if step.Field == "" {
+			return fmt.Errorf("field is required")
+		}
+		switch step.Type {
+		case "string", "number", "int", "bool":
+		default:
+			return fmt.Errorf("unknown type %q (string, number, int or bool)", step.Type)
+		}
+	case TransformFlatten:
+	default:
+		return fmt.Errorf("unknown operation")
+	}
+	return nil
+}
+
+// ConcurrentSafe implements service.ConcurrentProcessor.
+func (t *Transformer) ConcurrentSafe() {}
+
+func (t *Transformer) Process(ctx context.Context, entry *domain.Entry) error {
+	for _, step := range t.steps {
+		if step.when != nil {
+			ok, err := step.when.Match(ctx, entry)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		applyTransformStep(&entry.OrderedMap, step.TransformStep)
+	}
+	return nil
+}
+
+// Close releases the condition matchers.
+func (t *Transformer) Close() error {
+	for _, step := range t.steps {
+		if c, ok := step.when.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+	return nil
+}
+
+func applyTransformStep(m *orderedmap.OrderedMap, step TransformStep) {
+	switch step.Op {
+	case TransformRename:
+		if v, ok := lookupPath(m, step.From); ok {
+			deletePath(m, step.From)
+			setPath(m, step.To, v)
+		}
+	case TransformCopy:
+		if v, ok := lookupPath(m, step.From); ok {
+			setPath(m, step.To, v)
+		}
+	case TransformDrop:
+		for _, f := range step.Fields {
+			deletePath(m, f)
+		}
+	case TransformKeep:
+		keepFields(m, step.Fields)
+	case TransformSet:
+		setPath(m, step.Field, step.Value)
+	case TransformCast:
+		if v, ok := lookupPath(m, step.Field); ok {
+			if cast, ok := castValue(v, step.Type); ok {
+				setPath(m, step.Field, cast)
+			}
+		}
+	case TransformFlatten:
+		sep := step.Separator
+		if sep == "" {
+			sep = "."
+		}
+		flattenFields(m, step.Field, sep)
+	}
+}
+
+// keepFields keeps the fields given, along with the well-known ones in any
+// of their spellings: timestamp, level, message, caller, stack trace and the
+// trace IDs.
+func keepFields(m *orderedmap.OrderedMap, fields []string) {
+	wellKnown := [][]string{
+		timestampKeys, levelKeys, messageKeys, {FieldCaller, FieldStacktrace},
+		traceIDKeys, spanIDKeys, parentSpanIDKeys,
+	}
+	keep := make(map[string]struct{}, len(fields))
+	paths := append([]string(nil), fields...)
+	for _, keys := range append(wellKnown, fields) {
+		for _, k := range keys {
+			keep[k] = struct{}{}
+		}
+	}
+	for _, keys := range wellKnown {
+		paths = append(paths, keys...)
+	}
+	kept := orderedmap.New()
+	for _, k := range m.Keys() {
+		if _, ok := keep[k]; ok {
+			v, _ := m.Get(k)
+			kept.Set(k, v)
+		}
+	}
+	// Nested paths are pulled up as dotted keys.
+	for _, f := range paths {
+		if _, ok := kept.Get(f); ok {
+			continue
+		}
+		if v, ok := lookupPath(m, f); ok {
+			kept.Set(f, v)
+		}
+	}
+	replaceMap(m, kept)
+}
+
+// replaceMap replaces the contents of m with those of src.
+func replaceMap(m, src *orderedmap.OrderedMap) {
+	for _, k := range append([]string(nil), m.Keys()...) {
+		m.Delete(k)
+	}
+	for _, k := range src.Keys() {
+		v, _ := src.Get(k)
+		m.Set(k, v)
+	}
+}
+
+func castValue(v interface{}, typ string) (interface{}, bool) {
+	switch typ {
+	case "string":
+		switch vv := v.(type) {
+		case string:
+			return vv, true
+		case float64:
+			return strconv.FormatFloat(vv, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(vv), true
+		case nil:
+			return nil, false
+		default:
+			data, err := json.Marshal(vv)
+			return string(data), err == nil
+		}
+	case "number", "int":
+		var f float64
+		switch vv := v.(type) {
+		case float64:
+			f = vv
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(vv), 64)
+			if err != nil {
+				return nil, false
+			}
+			f = parsed
+		case bool:
+			if vv {
+				f = 1
+			}
+		default:
+			return nil, false
+		}
+		if typ == "int" {
+			f = float64(int64(f))
+		}
+		return f, true
+	case "bool":
+		switch vv := v.(type) {
+		case bool:
+			return vv, true
+		case float64:
+			return vv != 0, true
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(vv))
+			return b, err == nil
+		}
+	}
+	return nil, false
+}
+
+// flattenFields replaces nested objects with dotted top-level keys, in
+// place. Only field is flattened when given.
+func flattenFields(m *orderedmap.OrderedMap, field, sep string) {
+	flat := orderedmap.New()
+	for _, k := range m.Keys() {
+		v, _ := m.Get(k)
+		nested, ok := v.(orderedmap.OrderedMap)
+		if !ok || (field != "" && k != field) {
+			flat.Set(k, v)
+			continue
+		}
+		flattenInto(flat, k, nested, sep)
+	}
+	replaceMap(m, flat)
+}
+
+func flattenInto(dst *orderedmap.OrderedMap, prefix string, m orderedmap.OrderedMap, sep string) {
+	for _, k := range m.Keys() {
+		v, _ := m.Get(k)
+		key := prefix + sep + k
+		if nested, ok := v.(orderedmap.OrderedMap); ok {
+			flattenInto(dst, key, nested, sep)
+			continue
+		}
+		dst.Set(key, v)
+	}
+}
@@ -0,0 +1,190 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/domain"
+)
+
+func TestTransformer_Process(t *testing.T) {
+	ctx := context.Background()
+
+	newEntry := func() *domain.Entry {
+		http := orderedmap.New()
+		http.Set("status", "503")
+		http.Set("path", "/api")
+		entry := domain.NewEntry()
+		entry.Set("ts", "2026-01-01T12:00:00Z")
+		entry.Set("level", "error")
+		entry.Set("msg", "failed")
+		entry.Set("userId", "u1")
+		entry.Set("http", *http)
+		entry.Set("debug", true)
+		return entry
+	}
+	noMatcher := func(string) (EntryMatcher, error) {
+		return nil, errors.New("unexpected condition")
+	}
+
+	tests := []struct {
+		name      string
+		steps     []TransformStep
+		wantKeys  []string
+		wantPairs map[string]interface{}
+	}{
+		{
+			name:      "rename",
+			steps:     []TransformStep{{Op: TransformRename, From: "userId", To: "user_id"}},
+			wantKeys:  []string{"ts", "level", "msg", "http", "debug", "user_id"},
+			wantPairs: map[string]interface{}{"user_id": "u1"},
+		},
+		{
+			name:      "rename nested",
+			steps:     []TransformStep{{Op: TransformRename, From: "http.status", To: "status"}},
+			wantKeys:  []string{"ts", "level", "msg", "userId", "http", "debug", "status"},
+			wantPairs: map[string]interface{}{"status": "503", "http.path": "/api"},
+		},
+		{
+			name:      "copy",
+			steps:     []TransformStep{{Op: TransformCopy, From: "userId", To: "uid"}},
+			wantKeys:  []string{"ts", "level", "msg", "userId", "http", "debug", "uid"},
+			wantPairs: map[string]interface{}{"uid": "u1", "userId": "u1"},
+		},
+		{
+			name:     "drop",
+			steps:    []TransformStep{{Op: TransformDrop, Fields: []string{"debug", "http.path", "missing"}}},
+			wantKeys: []string{"ts", "level", "msg", "userId", "http"},
+		},
+		{
+			name:      "keep preserves the well-known keys",
+			steps:     []TransformStep{{Op: TransformKeep, Fields: []string{"userId", "http.status"}}},
+			wantKeys:  []string{"ts", "level", "msg", "userId", "http.status"},
+			wantPairs: map[string]interface{}{"http.status": "503"},
+		},
+		{
+			name:      "set",
+			steps:     []TransformStep{{Op: TransformSet, Field: "env", Value: "staging"}},
+			wantKeys:  []string{"ts", "level", "msg", "userId", "http", "debug", "env"},
+			wantPairs: map[string]interface{}{"env": "staging"},
+		},
+		{
+			name: "cast",
+			steps: []TransformStep{
+				{Op: TransformCast, Field: "http.status", Type: "number"},
+				{Op: TransformCast, Field: "debug", Type: "string"},
+				{Op: TransformCast, Field: "userId", Type: "int"},
+			},
+			wantPairs: map[string]interface{}{"http.status": float64(503), "debug": "true", "userId": "u1"},
+		},
+		{
+			name:      "flatten",
+			steps:     []TransformStep{{Op: TransformFlatten, Separator: "_"}},
+			wantKeys:  []string{"ts", "level", "msg", "userId", "http_status", "http_path", "debug"},
+			wantPairs: map[string]interface{}{"http_status": "503"},
+		},
+		{
+			name: "steps run in order",
+			steps: []TransformStep{
+				{Op: TransformRename, From: "userId", To: "user_id"},
+				{Op: TransformCopy, From: "user_id", To: "actor"},
+			},
+			wantPairs: map[string]interface{}{"actor": "u1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := NewTransformer(tt.steps, noMatcher)
+			require.NoError(t, err)
+			entry := newEntry()
+			require.NoError(t, tr.Process(ctx, entry))
+			if tt.wantKeys != nil {
+				assert.Equal(t, tt.wantKeys, entry.Keys())
+			}
+			for path, want := range tt.wantPairs {
+				got, ok := lookupPath(&entry.OrderedMap, path)
+				require.True(t, ok, path)
+				assert.Equal(t, want, got, path)
+			}
+		})
+	}
+
+	t.Run("should keep the well-known keys in any spelling", func(t *testing.T) {
+		tr, err := NewTransformer([]TransformStep{{Op: TransformKeep, Fields: []string{"userId"}}}, noMatcher)
+		require.NoError(t, err)
+		dd := orderedmap.New()
+		dd.Set("span_id", "42")
+		dd.Set("env", "prod")
+		entry := domain.NewEntry()
+		entry.Set("timestamp", "2026-01-01T12:00:00Z")
+		entry.Set("message", "failed")
+		entry.Set("trace_id", "4bf92f3577b34da6")
+		entry.Set("dd", *dd)
+		entry.Set("userId", "u1")
+		entry.Set("debug", true)
+		require.NoError(t, tr.Process(ctx, entry))
+		assert.Equal(t, []string{"timestamp", "message", "trace_id", "userId", "dd.span_id"}, entry.Keys())
+
+		logEntry := mapToLogEntry(entry)
+		assert.Equal(t, "failed", logEntry.Message)
+		assert.Equal(t, "4bf92f3577b34da6", logEntry.TraceID)
+		assert.Equal(t, "42", logEntry.SpanID)
+	})
+
+	t.Run("should apply conditional steps to matching entries only", func(t *testing.T) {
+		for _, matches := range []bool{true, false} {
+			var gotExpr string
+			tr, err := NewTransformer([]TransformStep{
+				{Op: TransformSet, Field: "team", Value: "core", When: "level:error"},
+			}, func(expr string) (EntryMatcher, error) {
+				gotExpr = expr
+				return fakeMatcher{result: matches}, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "level:error", gotExpr)
+
+			entry := newEntry()
+			require.NoError(t, tr.Process(ctx, entry))
+			_, ok := entry.Get("team")
+			assert.Equal(t, matches, ok)
+		}
+	})
+}
+
+func TestNewTransformer(t *testing.T) {
+	noMatcher := func(string) (EntryMatcher, error) {
+		return nil, errors.New("bad query")
+	}
+	for _, step := range []TransformStep{
+		{Op: "explode"},
+		{Op: TransformRename, From: "a"},
+		{Op: TransformDrop},
+		{Op: TransformCast, Field: "a", Type: "date"},
+		{Op: TransformSet, Field: "a", When: "level:("},
+	} {
+		_, err := NewTransformer([]TransformStep{step}, noMatcher)
+		assert.Error(t, err, "%+v", step)
+	}
+}
+
+func TestLoadTransformSteps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transform.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"op": "rename", "from": "userId", "to": "user_id"},
+		{"op": "cast", "field": "status", "type": "number", "when": "service:billing"}
+	]`), 0o600))
+
+	steps, err := LoadTransformSteps(path)
+	require.NoError(t, err)
+	assert.Equal(t, []TransformStep{
+		{Op: TransformRename, From: "userId", To: "user_id"},
+		{Op: TransformCast, Field: "status", Type: "number", When: "service:billing"},
+	}, steps)
+}
@@ -0,0 +1,263 @@
+// Package stacktrace parses the stack traces logged by Go, Java, Python and
+// Node programs into frames, so the terminal output and the web UI can
+// highlight them and fold the frames of libraries.
+package stacktrace
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Language is the runtime that printed a stack trace.
+type Language string
+
+const (
+	Go     Language = "go"
+	Java   Language = "java"
+	Python Language = "python"
+	Node   Language = "node"
+)
+
+// Frame is a function call of a stack trace.
+type Frame struct {
+	Function string
+	File     string
+	// Line and Column are 0 when unknown.
+	Line   int
+	Column int
+	// Code is the source line Python prints under the frame.
+	Code string
+	// Library is set for the frames of the standard library, vendored
+	// packages, Go modules and node_modules.
+	Library bool
+}
+
+// BlockKind tells the Go panic blocks apart.
+type BlockKind string
+
+const (
+	// KindPanic is the "panic: ..." (or "fatal error: ...") line of a Go
+	// panic.
+	KindPanic BlockKind = "panic"
+	// KindGoroutine is a "goroutine 1 [running]:" header of a Go dump.
+	KindGoroutine BlockKind = "goroutine"
+)
+
+// Block is a part of a stack trace: a line telling what happened (panic:,
+// goroutine 1 [running]:, an exception, Caused by:...) followed by the
+// frames printed under it. Either may be empty.
+type Block struct {
+	// Kind is empty for the blocks that are not from Go panics.
+	Kind   BlockKind
+	Header string
+	Frames []Frame
+}
+
+// Trace is a parsed stack trace.
+type Trace struct {
+	Language Language
+	Blocks   []Block
+}
+
+// Frames counts the frames of all the blocks.
+func (t Trace) Frames() int {
+	n := 0
+	for _, b := range t.Blocks {
+		n += len(b.Frames)
+	}
+	return n
+}
+
+var (
+	// goFileLine is the second line of a Go frame:
+	// "\t/app/main.go:12 +0x1d".
+	goFileLine = regexp.MustCompile(`^\t(.+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	// goArgs are the arguments Go prints after the function of a frame.
+	goArgs = regexp.MustCompile(`\((?:0x[0-9a-f]+|\.\.\.|\?|, |\{[^}]*\})*\)$`)
+	// nodeFrame is "at fn (file:line:col)", "at file:line:col" or
+	// "at async fn (file:line:col)".
+	nodeFrame = regexp.MustCompile(`^\s+at (?:(.+?) \()?(.+?):(\d+):(\d+)\)?$`)
+	// javaFrame is "at pkg.Class.method(File.java:10)", the file possibly
+	// "Native Method" or "Unknown Source".
+	javaFrame = regexp.MustCompile(`^\s+at ([^\s(]+)\(([^:)]*)(?::(\d+))?\)$`)
+	// goroutineHeader starts the frames of a goroutine in Go dumps.
+	goroutineHeader = regexp.MustCompile(`^goroutine \d+ \[[^\]]+\]:$`)
+	// pythonFrame is `File "/app/main.py", line 10, in main`.
+	pythonFrame = regexp.MustCompile(`^\s+File "(.+)", line (\d+), in (.+)$`)
+)
+
+// Parse splits a stack trace into blocks and frames, reporting whether any
+// frame was found.
+func Parse(s string) (Trace, bool) {
+	var (
+		t     Trace
+		block *Block
+	)
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), "\n")
+	newBlock := func(header string) {
+		t.Blocks = append(t.Blocks, Block{Kind: blockKind(header), Header: header})
+		block = &t.Blocks[len(t.Blocks)-1]
+	}
+	addFrame := func(lang Language, f Frame) {
+		if t.Language == "" {
+			t.Language = lang
+		}
+		if block == nil {
+			newBlock("")
+		}
+		block.Frames = append(block.Frames, f)
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if m := nodeFrame.FindStringSubmatch(line); m != nil {
+			addFrame(Node, nodeFrameOf(m))
+			continue
+		}
+		if m := javaFrame.FindStringSubmatch(line); m != nil {
+			addFrame(Java, javaFrameOf(m))
+			continue
+		}
+		if m := pythonFrame.FindStringSubmatch(line); m != nil {
+			f := pythonFrameOf(m)
+			// The source line, when available, is indented further.
+			if i+1 < len(lines) && isPythonCode(lines[i+1]) {
+				f.Code = strings.TrimSpace(lines[i+1])
+				i++
+			}
+			// So is the line pointing at the failing expression (^^^^).
+			if i+1 < len(lines) && isPythonMarker(lines[i+1]) {
+				i++
+			}
+			addFrame(Python, f)
+			continue
+		}
+		if i+1 < len(lines) && !strings.HasPrefix(line, "\t") {
+			if m := goFileLine.FindStringSubmatch(lines[i+1]); m != nil {
+				addFrame(Go, goFrameOf(line, m))
+				i++
+				continue
+			}
+		}
+		newBlock(strings.TrimSpace(line))
+	}
+	return t, t.Frames() > 0
+}
+
+func blockKind(header string) BlockKind {
+	switch {
+	case strings.HasPrefix(header, "panic: "), strings.HasPrefix(header, "fatal error: "):
+		return KindPanic
+	case goroutineHeader.MatchString(header):
+		return KindGoroutine
+	default:
+		return ""
+	}
+}
+
+func goFrameOf(function string, m []string) Frame {
+	function = strings.TrimPrefix(function, "created by ")
+	// Since Go 1.21: "created by main.start in goroutine 1".
+	if i := strings.Index(function, " in goroutine "); i >= 0 {
+		function = function[:i]
+	}
+	function = goArgs.ReplaceAllString(function, "")
+	line, _ := strconv.Atoi(m[2])
+	return Frame{
+		Function: function,
+		File:     m[1],
+		Line:     line,
+		Library:  isGoLibrary(function, m[1]),
+	}
+}
+
+// isGoLibrary reports whether a Go frame is from a dependency (in the module
+// cache, vendored, or versioned as -trimpath builds print them) or from the
+// standard library, whose files are in GOROOT/src under their import path.
+// The import path alone does not tell: the modules of programs may have no
+// domain either (myapp/internal/db).
+func isGoLibrary(function, file string) bool {
+	if strings.Contains(file, "/vendor/") || strings.Contains(file, "/pkg/mod/") || strings.Contains(file, "@v") {
+		return true
+	}
+	pkg := goPackage(function)
+	first, _, _ := strings.Cut(pkg, "/")
+	return !strings.Contains(first, ".") && strings.Contains(file, "/src/"+pkg+"/")
+}
+
+// goPackage returns the import path of the package of a Go function:
+// net/http for net/http.(*conn).serve.
+func goPackage(function string) string {
+	dir, name := "", function
+	if i := strings.LastIndexByte(function, '/'); i >= 0 {
+		dir, name = function[:i+1], function[i+1:]
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return dir + name
+}
+
+func nodeFrameOf(m []string) Frame {
+	line, _ := strconv.Atoi(m[3])
+	column, _ := strconv.Atoi(m[4])
+	file := m[2]
+	return Frame{
+		Function: strings.TrimPrefix(m[1], "async "),
+		File:     file,
+		Line:     line,
+		Column:   column,
+		Library: strings.HasPrefix(file, "node:") ||
+			strings.HasPrefix(file, "internal/") ||
+			strings.Contains(file, "/node_modules/"),
+	}
+}
+
+var javaLibraryPrefixes = []string{"java.", "javax.", "jdk.", "sun.", "com.sun.", "kotlin.", "kotlinx.", "scala."}
+
+func javaFrameOf(m []string) Frame {
+	function := m[1]
+	// Since Java 9, the module prefixes the class: java.base/java.lang.Thread.
+	if i := strings.IndexByte(function, '/'); i >= 0 {
+		function = function[i+1:]
+	}
+	line, _ := strconv.Atoi(m[3])
+	f := Frame{Function: function, File: m[2], Line: line}
+	for _, prefix := range javaLibraryPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			f.Library = true
+			break
+		}
+	}
+	return f
+}
+
+func pythonFrameOf(m []string) Frame {
+	line, _ := strconv.Atoi(m[2])
+	file := m[1]
+	return Frame{
+		Function: m[3],
+		File:     file,
+		Line:     line,
+		Library: strings.Contains(file, "/lib/python") ||
+			strings.Contains(file, "site-packages") ||
+			strings.Contains(file, "dist-packages") ||
+			strings.HasPrefix(file, "<frozen "),
+	}
+}
+
+// isPythonCode reports whether a line is the source Python prints under a
+// frame: indented, and neither a frame nor a marker line (^^^^).
+func isPythonCode(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" &&
+		strings.HasPrefix(line, "    ") &&
+		!pythonFrame.MatchString(line) &&
+		!isPythonMarker(line)
+}
+
+func isPythonMarker(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && strings.Trim(trimmed, "^~") == ""
+}
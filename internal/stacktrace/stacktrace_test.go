@@ -0,0 +1,121 @@
+package stacktrace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should parse Go panics", func(t *testing.T) {
+		got, ok := Parse("panic: boom\n\ngoroutine 1 [running]:\n" +
+			"main.(*Server).handle(0xc000012345, {0x4a2b, 0x3})\n\t/app/server.go:42 +0x1d\n" +
+			"net/http.HandlerFunc.ServeHTTP(...)\n\t/usr/local/go/src/net/http/server.go:2136\n" +
+			"github.com/go-chi/chi/v5.(*Mux).ServeHTTP(0xc0000a0000, {0x7f, 0xc0}, 0xc0001)\n\t/go/pkg/mod/github.com/go-chi/chi/v5@v5.0.8/mux.go:87 +0x2f4\n" +
+			"created by main.start in goroutine 1\n\t/app/main.go:10 +0x65\n")
+		require.True(t, ok)
+		assert.Equal(t, Trace{
+			Language: Go,
+			Blocks: []Block{
+				{Kind: KindPanic, Header: "panic: boom"},
+				{Kind: KindGoroutine, Header: "goroutine 1 [running]:", Frames: []Frame{
+					{Function: "main.(*Server).handle", File: "/app/server.go", Line: 42},
+					{Function: "net/http.HandlerFunc.ServeHTTP", File: "/usr/local/go/src/net/http/server.go", Line: 2136, Library: true},
+					{Function: "github.com/go-chi/chi/v5.(*Mux).ServeHTTP", File: "/go/pkg/mod/github.com/go-chi/chi/v5@v5.0.8/mux.go", Line: 87, Library: true},
+					{Function: "main.start", File: "/app/main.go", Line: 10},
+				}},
+			},
+		}, got)
+	})
+
+	t.Run("should parse zap stack traces", func(t *testing.T) {
+		got, ok := Parse("main.run\n\t/app/main.go:20\nruntime.main\n\t/usr/local/go/src/runtime/proc.go:250")
+		require.True(t, ok)
+		assert.Equal(t, []Block{{Frames: []Frame{
+			{Function: "main.run", File: "/app/main.go", Line: 20},
+			{Function: "runtime.main", File: "/usr/local/go/src/runtime/proc.go", Line: 250, Library: true},
+		}}}, got.Blocks)
+	})
+
+	t.Run("should tell the standard library from modules without a domain", func(t *testing.T) {
+		got, ok := Parse("myapp/internal/db.Open(...)\n\t/app/internal/db/db.go:12\n" +
+			"net.Dial(...)\n\t/usr/local/go/src/net/dial.go:331\n" +
+			"github.com/lib/pq.Open(...)\n\tgithub.com/lib/pq@v1.10.9/conn.go:300\n" +
+			"myapp/cmd.run()\n\tmyapp/cmd/run.go:8\n")
+		require.True(t, ok)
+		assert.Equal(t, []Block{{Frames: []Frame{
+			{Function: "myapp/internal/db.Open", File: "/app/internal/db/db.go", Line: 12},
+			{Function: "net.Dial", File: "/usr/local/go/src/net/dial.go", Line: 331, Library: true},
+			{Function: "github.com/lib/pq.Open", File: "github.com/lib/pq@v1.10.9/conn.go", Line: 300, Library: true},
+			{Function: "myapp/cmd.run", File: "myapp/cmd/run.go", Line: 8},
+		}}}, got.Blocks)
+	})
+
+	t.Run("should parse Java exceptions", func(t *testing.T) {
+		got, ok := Parse("java.lang.IllegalStateException: boom\n" +
+			"\tat com.example.Service.run(Service.java:10)\n" +
+			"\tat java.base/java.lang.Thread.run(Thread.java:833)\n" +
+			"Caused by: java.io.IOException: closed\n" +
+			"\tat sun.nio.ch.IOUtil.read(Native Method)\n" +
+			"\t... 5 more\n")
+		require.True(t, ok)
+		assert.Equal(t, Trace{
+			Language: Java,
+			Blocks: []Block{
+				{Header: "java.lang.IllegalStateException: boom", Frames: []Frame{
+					{Function: "com.example.Service.run", File: "Service.java", Line: 10},
+					{Function: "java.lang.Thread.run", File: "Thread.java", Line: 833, Library: true},
+				}},
+				{Header: "Caused by: java.io.IOException: closed", Frames: []Frame{
+					{Function: "sun.nio.ch.IOUtil.read", File: "Native Method", Library: true},
+				}},
+				{Header: "... 5 more"},
+			},
+		}, got)
+	})
+
+	t.Run("should parse Python tracebacks", func(t *testing.T) {
+		got, ok := Parse("Traceback (most recent call last):\n" +
+			"  File \"/app/main.py\", line 10, in <module>\n" +
+			"    main()\n" +
+			"    ^^^^^^\n" +
+			"  File \"/usr/lib/python3.11/json/__init__.py\", line 346, in loads\n" +
+			"    return _default_decoder.decode(s)\n" +
+			"ValueError: boom\n")
+		require.True(t, ok)
+		assert.Equal(t, Trace{
+			Language: Python,
+			Blocks: []Block{
+				{Header: "Traceback (most recent call last):", Frames: []Frame{
+					{Function: "<module>", File: "/app/main.py", Line: 10, Code: "main()"},
+					{Function: "loads", File: "/usr/lib/python3.11/json/__init__.py", Line: 346, Code: "return _default_decoder.decode(s)", Library: true},
+				}},
+				{Header: "ValueError: boom"},
+			},
+		}, got)
+	})
+
+	t.Run("should parse Node stacks", func(t *testing.T) {
+		got, ok := Parse("Error: boom\n" +
+			"    at Object.<anonymous> (/app/index.js:3:9)\n" +
+			"    at async handler (/app/node_modules/express/lib/router.js:10:5)\n" +
+			"    at node:internal/main/run_main_module:28:49\n")
+		require.True(t, ok)
+		assert.Equal(t, Trace{
+			Language: Node,
+			Blocks: []Block{
+				{Header: "Error: boom", Frames: []Frame{
+					{Function: "Object.<anonymous>", File: "/app/index.js", Line: 3, Column: 9},
+					{Function: "handler", File: "/app/node_modules/express/lib/router.js", Line: 10, Column: 5, Library: true},
+					{File: "node:internal/main/run_main_module", Line: 28, Column: 49, Library: true},
+				}},
+			},
+		}, got)
+	})
+
+	t.Run("should report text without frames", func(t *testing.T) {
+		_, ok := Parse("something went wrong\nreally")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,175 @@
+// Package timestamp parses the timestamps found in log entries: epoch numbers
+// in any unit and the usual textual layouts. It is shared by the terminal
+// output and the indexer so both read the same instant out of an entry.
+package timestamp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtinLayouts are tried, in order, after the user-provided ones. Layouts
+// without a zone are interpreted in the default location. Fractional seconds
+// (with a dot or a comma) are accepted after the seconds field by time.Parse
+// even when the layout does not mention them.
+var builtinLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -0700 MST", // time.Time.String()
+	time.DateTime,
+	"2006/01/02 15:04:05", // log package
+	"02/Jan/2006:15:04:05 -0700",
+	time.Layout,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	time.RFC822,
+	time.RFC822Z,
+	time.RFC850,
+	time.RFC1123,
+	time.RFC1123Z,
+	time.StampNano,
+	time.StampMicro,
+	time.StampMilli,
+	time.Stamp,
+	time.DateOnly,
+}
+
+var (
+	userLayouts []string
+
+	defaultLocation = time.Local
+)
+
+// RegisterLayout adds a Go time layout tried before the built-in ones.
+func RegisterLayout(layout string) {
+	userLayouts = append(userLayouts, layout)
+}
+
+// ResetLayouts drops the layouts added with RegisterLayout and returns a func
+// putting them back, for tests registering their own.
+func ResetLayouts() (restore func()) {
+	saved := userLayouts
+	userLayouts = nil
+	return func() {
+		userLayouts = saved
+	}
+}
+
+// SetDefaultLocation sets the time zone used for values carrying no zone
+// information. Defaults to the local time zone.
+func SetDefaultLocation(loc *time.Location) {
+	defaultLocation = loc
+}
+
+// Epoch magnitudes: anything below secondsLimit is seconds (up to year 5138),
+// and each following unit is a thousand times larger.
+const (
+	secondsLimit = 1e11
+	millisLimit  = 1e14
+	microsLimit  = 1e17
+)
+
+// Parse reads a timestamp from a decoded JSON value: epoch numbers (seconds,
+// milliseconds, microseconds or nanoseconds, detected by magnitude), numeric
+// strings, or strings in any of the known layouts.
+func Parse(v interface{}) (time.Time, bool) {
+	switch vv := v.(type) {
+	case string:
+		return parseString(vv)
+	case float64:
+		return fromEpochFloat(vv), true
+	case int64:
+		return fromEpochInt(vv), true
+	case int:
+		return fromEpochInt(int64(vv)), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func parseString(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	if isNumeric(s) {
+		// Integers are parsed as such: float64 cannot hold nanosecond epochs
+		// exactly.
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return fromEpochInt(n), true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return fromEpochFloat(f), true
+		}
+	}
+	for _, layouts := range [][]string{userLayouts, builtinLayouts} {
+		for _, layout := range layouts {
+			t, err := time.ParseInLocation(layout, s, defaultLocation)
+			if err != nil {
+				continue
+			}
+			if t.Year() == 0 {
+				// Yearless layouts (syslog stamps) refer to the current year.
+				t = t.AddDate(time.Now().In(t.Location()).Year(), 0, 0)
+			}
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isNumeric reports whether s is a (possibly negative) decimal number.
+func isNumeric(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	dot := false
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+		case r == '.' && !dot && i > 0:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return s != "" && !strings.HasSuffix(s, ".")
+}
+
+func fromEpochInt(n int64) time.Time {
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < secondsLimit:
+		return time.Unix(n, 0)
+	case abs < millisLimit:
+		return time.UnixMilli(n)
+	case abs < microsLimit:
+		return time.UnixMicro(n)
+	default:
+		return time.Unix(0, n)
+	}
+}
+
+func fromEpochFloat(f float64) time.Time {
+	abs := math.Abs(f)
+	perSecond := 1.0
+	switch {
+	case abs < secondsLimit:
+	case abs < millisLimit:
+		perSecond = 1e3
+	case abs < microsLimit:
+		perSecond = 1e6
+	default:
+		perSecond = 1e9
+	}
+	seconds, frac := math.Modf(f / perSecond)
+	return time.Unix(int64(seconds), int64(frac*float64(time.Second)))
+}
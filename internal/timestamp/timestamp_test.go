@@ -0,0 +1,83 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	want := time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC)
+
+	tests := []struct {
+		name  string
+		input interface{}
+		want  time.Time
+	}{
+		{"epoch seconds", float64(want.Unix()) + 0.123, want},
+		{"epoch milliseconds", float64(want.UnixMilli()), want},
+		{"epoch microseconds", float64(want.UnixMicro()), want},
+		{"epoch nanoseconds", float64(want.UnixNano()), want},
+		{"epoch milliseconds string", "1704207845123", want},
+		{"epoch nanoseconds string", "1704207845123000000", want},
+		{"epoch seconds string with fraction", "1704207845.123", want},
+		{"RFC3339", "2024-01-02T15:04:05.123Z", want},
+		{"offset without colon", "2024-01-02T17:04:05.123+0200", want},
+		{"comma fraction", "2024-01-02 15:04:05,123", want},
+		{"go time string", "2024-01-02 15:04:05.123 +0000 UTC", want},
+		{"log package", "2024/01/02 15:04:05.123", want},
+		{"common log format", "02/Jan/2024:12:04:05 -0300", want.Truncate(time.Second)},
+	}
+
+	SetDefaultLocation(time.UTC)
+	t.Cleanup(func() {
+		SetDefaultLocation(time.Local)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.input)
+			require.True(t, ok)
+			assert.WithinDuration(t, tt.want, got, time.Microsecond, "got %s", got)
+		})
+	}
+
+	t.Run("should fail on unparseable values", func(t *testing.T) {
+		for _, v := range []interface{}{"yesterday", "", true, nil} {
+			_, ok := Parse(v)
+			assert.False(t, ok, "%v", v)
+		}
+	})
+}
+
+func TestParse_defaultLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-3", -3*60*60)
+	SetDefaultLocation(loc)
+	t.Cleanup(func() {
+		SetDefaultLocation(time.Local)
+	})
+
+	got, ok := Parse("2024-01-02 12:04:05")
+	require.True(t, ok)
+	assert.True(t, got.Equal(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)), "got %s", got)
+
+	// Explicit zones win over the default one.
+	got, ok = Parse("2024-01-02T15:04:05Z")
+	require.True(t, ok)
+	assert.True(t, got.Equal(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)), "got %s", got)
+}
+
+func TestRegisterLayout(t *testing.T) {
+	t.Cleanup(ResetLayouts())
+
+	_, ok := Parse("02.01.2024 15h04")
+	require.False(t, ok)
+
+	RegisterLayout("02.01.2006 15h04")
+	got, ok := Parse("02.01.2024 15h04")
+	require.True(t, ok)
+	assert.Equal(t, 2024, got.Year())
+	assert.Equal(t, 15, got.Hour())
+}
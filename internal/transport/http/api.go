@@ -16,9 +16,11 @@ import (
 
 type EntryReader interface {
 	Search(ctx context.Context, req entryreader.SearchRequest) (entryreader.SearchResponse, error)
-	Fields(ctx context.Context) ([]string, error)
+	Fields(ctx context.Context) ([]entryreader.Field, error)
 	FieldValues(ctx context.Context, field, prefix string, limit int) ([]entryreader.FieldValue, error)
 	Histogram(ctx context.Context, req entryreader.HistogramRequest) (entryreader.HistogramResponse, error)
+	Trace(ctx context.Context, traceID string) (entryreader.TraceResponse, error)
+	Explain(ctx context.Context, q string) (entryreader.Explanation, error)
 }
 
 type API struct {
@@ -27,6 +29,9 @@ type API struct {
 	reader   EntryReader
 	uiFS     fs.FS
 	baseCtx  context.Context
+
+	indexStats IndexStats
+	rejects    Rejects
 }
 
 type Option func(*API)
@@ -82,9 +87,13 @@ func (api *API) setupHandlers(app *fiber.App) {
 
 	app.Get("/entries/search", api.EntriesSearch)
 	app.Get("/entries/histogram", api.EntriesHistogram)
+	app.Get("/entries/stats", api.EntriesStats)
+	app.Get("/entries/rejects", api.EntriesRejects)
 	app.Get("/entries/fields", api.EntriesFields)
 	app.Get("/entries/fields/:field/values", api.EntriesFieldValues)
+	app.Get("/entries/query/explain", api.EntriesQueryExplain)
 	app.Get("/entries/live", fiberws.New(api.HandleWebsocket))
+	app.Get("/traces/:id", api.Trace)
 
 	if api.uiFS != nil {
 		app.Get("/*", static.New("", static.Config{
@@ -1,12 +1,26 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/blevesearch/bleve/v2"
 	"github.com/gofiber/fiber/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/jamillosantos/lovr/internal/deadletter"
+	"github.com/jamillosantos/lovr/internal/domain"
+	"github.com/jamillosantos/lovr/internal/parsers"
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+	"github.com/jamillosantos/lovr/internal/service/processors"
+	"github.com/jamillosantos/lovr/internal/transport/http/models"
 )
 
 func TestAPI_setupHandlers(t *testing.T) {
@@ -26,3 +40,172 @@ func TestAPI_setupHandlers(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
 }
+
+type fakeIndexStats processors.IndexerStats
+
+func (s fakeIndexStats) Stats() processors.IndexerStats {
+	return processors.IndexerStats(s)
+}
+
+func TestAPI_EntriesStats(t *testing.T) {
+	api := New(nil, WithIndexStats(fakeIndexStats{Pending: 12, Lag: 340 * time.Millisecond, Indexed: 1000}))
+	app := fiber.New()
+	api.setupHandlers(app)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/entries/stats", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"pending":12,"lagMs":340,"indexed":1000}`, string(body))
+}
+
+func TestAPI_EntriesRejects(t *testing.T) {
+	sink := deadletter.New("app.log")
+	sink.Reject(&parsers.LineError{Line: 7, Raw: "oops", Err: errors.New("invalid JSON at line 7")})
+	api := New(nil, WithRejects(sink))
+	app := fiber.New()
+	api.setupHandlers(app)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/entries/rejects", nil))
+	require.NoError(t, err)
+	var got models.RejectsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	assert.Equal(t, uint64(1), got.Count)
+	require.Len(t, got.Rejects, 1)
+	assert.Equal(t, "app.log", got.Rejects[0].Source)
+	assert.Equal(t, 7, got.Rejects[0].Line)
+	assert.Equal(t, "oops", got.Rejects[0].Raw)
+	assert.Equal(t, "invalid JSON at line 7", got.Rejects[0].Reason)
+}
+
+func TestAPI_Trace(t *testing.T) {
+	ctx := context.Background()
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+	indexer := processors.NewIndexer(index)
+	for i, span := range [][2]string{{"s1", ""}, {"s2", "s1"}} {
+		entry := domain.NewEntry()
+		entry.Set("ts", time.Date(2026, 1, 1, 12, 0, i, 0, time.UTC).Format(time.RFC3339))
+		entry.Set("msg", "step")
+		entry.Set("dd.trace_id", "abc")
+		entry.Set("dd.span_id", span[0])
+		if span[1] != "" {
+			entry.Set("dd.parent_id", span[1])
+		}
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	api := New(entryreader.NewReader(index))
+	app := fiber.New()
+	api.setupHandlers(app)
+
+	t.Run("should return the entries and the span tree", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/traces/abc", nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var got models.TraceResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, "abc", got.TraceID)
+		require.Len(t, got.Entries, 2)
+		assert.Equal(t, "s1", got.Entries[0].SpanID)
+		require.Len(t, got.Spans, 1)
+		assert.Equal(t, []string{got.Entries[0].ID}, got.Spans[0].EntryIDs)
+		require.Len(t, got.Spans[0].Children, 1)
+		assert.Equal(t, "s2", got.Spans[0].Children[0].SpanID)
+		assert.Equal(t, "s1", got.Spans[0].Children[0].ParentSpanID)
+	})
+
+	t.Run("should return 404 for unknown traces", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/traces/unknown", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestAPI_EntriesQueryExplain(t *testing.T) {
+	ctx := context.Background()
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+	indexer := processors.NewIndexer(index)
+	for _, level := range []string{"error", "info", "error"} {
+		entry := domain.NewEntry()
+		entry.Set("level", level)
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	api := New(entryreader.NewReader(index))
+	app := fiber.New()
+	api.setupHandlers(app)
+
+	explain := func(t *testing.T, q string) models.QueryExplainResponse {
+		resp, err := app.Test(httptest.NewRequest("GET", "/entries/query/explain?q="+url.QueryEscape(q), nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var got models.QueryExplainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		return got
+	}
+
+	t.Run("should return the tree with the hits of each clause", func(t *testing.T) {
+		got := explain(t, "level:error OR level:info")
+		assert.True(t, got.Valid)
+		assert.Nil(t, got.Error)
+		require.NotNil(t, got.Tree)
+		assert.Equal(t, "or", got.Tree.Kind)
+		require.NotNil(t, got.Tree.Hits)
+		assert.Equal(t, uint64(3), *got.Tree.Hits)
+		require.Len(t, got.Tree.Children, 2)
+		assert.Equal(t, "level", got.Tree.Children[0].Field)
+		require.NotNil(t, got.Tree.Children[0].Hits)
+		assert.Equal(t, uint64(2), *got.Tree.Children[0].Hits)
+		assert.Contains(t, got.Text, "OR  (3 hits)")
+	})
+
+	t.Run("should report syntax errors with their position", func(t *testing.T) {
+		got := explain(t, "level:error)")
+		assert.False(t, got.Valid)
+		assert.Nil(t, got.Tree)
+		require.NotNil(t, got.Error)
+		assert.Equal(t, 11, got.Error.Position)
+		assert.Equal(t, `invalid query: unexpected ")"`, got.Error.Message)
+	})
+}
+
+func TestAPI_EntriesFields(t *testing.T) {
+	ctx := context.Background()
+	index, err := bleve.NewMemOnly(processors.NewIndexMapping())
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+	indexer := processors.NewIndexer(index)
+	for _, status := range []interface{}{503.0, "503"} {
+		entry := domain.NewEntry()
+		entry.Set("msg", "step")
+		entry.Set("status", status)
+		require.NoError(t, indexer.Process(ctx, entry))
+	}
+	require.NoError(t, indexer.Flush())
+
+	api := New(entryreader.NewReader(index))
+	app := fiber.New()
+	api.setupHandlers(app)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/entries/fields", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var got models.FieldsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	assert.Contains(t, got.Fields, "status")
+	assert.Equal(t, []string{"number", "string"}, got.Types["status"])
+	assert.Equal(t, []string{"string"}, got.Types["message"])
+	assert.Equal(t, []string{"status"}, got.Conflicts)
+}
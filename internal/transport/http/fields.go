@@ -16,7 +16,7 @@ func (api *API) EntriesFields(fctx fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
-	return fctx.JSON(models.FieldsResponse{Fields: fields})
+	return fctx.JSON(models.MapFieldsResponse(fields))
 }
 
 func (api *API) EntriesFieldValues(fctx fiber.Ctx) error {
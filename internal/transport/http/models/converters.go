@@ -26,6 +26,12 @@ func DomainToLogEntry(e *domain.LogEntry, dst *Entry) {
 		Fields:     DomainToLogFields(e.Fields),
 		Caller:     e.Caller,
 		Stacktrace: e.Stacktrace,
+		Stack:      MapStackTrace(e.Stacktrace),
+		Raw:        e.Raw,
+
+		TraceID:      e.TraceID,
+		SpanID:       e.SpanID,
+		ParentSpanID: e.ParentSpanID,
 	}
 }
 
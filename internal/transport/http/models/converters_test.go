@@ -57,6 +57,7 @@ func Test_domainToLogEntry(t *testing.T) {
 		},
 		Caller:     "caller",
 		Stacktrace: "stacktrace",
+		Raw:        "raw",
 	}
 	fields := orderedmap.New()
 	fields.Set(want.Fields[0].Key, want.Fields[0].Value)
@@ -68,6 +69,7 @@ func Test_domainToLogEntry(t *testing.T) {
 		Fields:     *fields,
 		Caller:     want.Caller,
 		Stacktrace: want.Stacktrace,
+		Raw:        want.Raw,
 	}, &got)
 	assert.Equal(t, want, got)
 }
@@ -94,3 +96,23 @@ func Test_domainToLogFields(t *testing.T) {
 		}, got)
 	})
 }
+
+func TestMapStackTrace(t *testing.T) {
+	t.Run("should split the stack trace into frames", func(t *testing.T) {
+		got := MapStackTrace("panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d\n")
+		assert.Equal(t, &StackTrace{
+			Language: "go",
+			Blocks: []StackBlock{
+				{Kind: "panic", Header: "panic: boom"},
+				{Kind: "goroutine", Header: "goroutine 1 [running]:", Frames: []StackFrame{
+					{Function: "main.main", File: "/app/main.go", Line: 12},
+				}},
+			},
+		}, got)
+	})
+
+	t.Run("should return nil for stack traces without frames", func(t *testing.T) {
+		assert.Nil(t, MapStackTrace(""))
+		assert.Nil(t, MapStackTrace("stacktrace"))
+	})
+}
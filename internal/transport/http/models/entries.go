@@ -15,6 +15,12 @@ type Entry struct {
 	Fields     []*Field     `json:"fields,omitempty"`
 	Caller     string       `json:"caller,omitempty"`
 	Stacktrace string       `json:"stacktrace,omitempty"`
+	Stack      *StackTrace  `json:"stack,omitempty"`
+	Raw        string       `json:"raw,omitempty"`
+
+	TraceID      string `json:"traceId,omitempty"`
+	SpanID       string `json:"spanId,omitempty"`
+	ParentSpanID string `json:"parentSpanId,omitempty"`
 }
 
 type Field struct {
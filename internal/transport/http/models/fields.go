@@ -6,6 +6,29 @@ import (
 
 type FieldsResponse struct {
 	Fields []string `json:"fields"`
+	// Types lists the types each field was indexed as: string, number,
+	// boolean or date.
+	Types map[string][]string `json:"types"`
+	// Conflicts are the fields indexed with more than one type.
+	Conflicts []string `json:"conflicts"`
+}
+
+func MapFieldsResponse(fields []entryreader.Field) FieldsResponse {
+	r := FieldsResponse{
+		Fields:    make([]string, len(fields)),
+		Types:     make(map[string][]string, len(fields)),
+		Conflicts: make([]string, 0),
+	}
+	for i, f := range fields {
+		r.Fields[i] = f.Name
+		if len(f.Types) > 0 {
+			r.Types[f.Name] = f.Types
+		}
+		if f.Conflict() {
+			r.Conflicts = append(r.Conflicts, f.Name)
+		}
+	}
+	return r
 }
 
 type FieldValue struct {
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+)
+
+type QueryNode struct {
+	Kind      string      `json:"kind"`
+	Field     string      `json:"field,omitempty"`
+	Value     string      `json:"value,omitempty"`
+	Terms     []string    `json:"terms,omitempty"`
+	Operator  string      `json:"operator,omitempty"`
+	Min       int         `json:"min,omitempty"`
+	Fuzziness int         `json:"fuzziness,omitempty"`
+	Hits      *uint64     `json:"hits,omitempty"`
+	Children  []QueryNode `json:"children,omitempty"`
+}
+
+type QueryError struct {
+	Message string `json:"message"`
+	// Position is the character offset of the error in the query.
+	Position int `json:"position"`
+}
+
+type QueryExplainResponse struct {
+	Query string      `json:"query"`
+	Valid bool        `json:"valid"`
+	Tree  *QueryNode  `json:"tree,omitempty"`
+	Text  string      `json:"text"`
+	Error *QueryError `json:"error,omitempty"`
+}
+
+func MapQueryExplainResponse(e entryreader.Explanation) QueryExplainResponse {
+	r := QueryExplainResponse{
+		Query: e.Query,
+		Valid: e.Error == nil,
+		Text:  e.Text(),
+	}
+	if e.Tree != nil {
+		tree := mapQueryNode(e.Tree)
+		r.Tree = &tree
+	}
+	if e.Error != nil {
+		r.Error = &QueryError{
+			Message:  e.Error.Error(),
+			Position: e.Error.Pos,
+		}
+	}
+	return r
+}
+
+func mapQueryNode(node *entryreader.ExplainNode) QueryNode {
+	r := QueryNode{
+		Kind:      node.Kind,
+		Field:     node.Field,
+		Value:     node.Value,
+		Terms:     node.Terms,
+		Operator:  node.Operator,
+		Min:       node.Min,
+		Fuzziness: node.Fuzziness,
+		Hits:      node.Hits,
+	}
+	for _, child := range node.Children {
+		r.Children = append(r.Children, mapQueryNode(child))
+	}
+	return r
+}
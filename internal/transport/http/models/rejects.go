@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jamillosantos/lovr/internal/deadletter"
+)
+
+type Reject struct {
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+	Source string    `json:"source"`
+	Line   int       `json:"line,omitempty"`
+	Raw    string    `json:"raw"`
+}
+
+type RejectsResponse struct {
+	Count   uint64   `json:"count"`
+	Rejects []Reject `json:"rejects"`
+}
+
+func MapRejectsResponse(count uint64, rejects []deadletter.Reject) RejectsResponse {
+	r := RejectsResponse{
+		Count:   count,
+		Rejects: make([]Reject, len(rejects)),
+	}
+	for i, reject := range rejects {
+		r.Rejects[i] = Reject(reject)
+	}
+	return r
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/jamillosantos/lovr/internal/stacktrace"
+)
+
+// StackTrace is the stack trace of an entry split into frames.
+type StackTrace struct {
+	Language string       `json:"language"`
+	Blocks   []StackBlock `json:"blocks"`
+}
+
+type StackBlock struct {
+	// Kind is panic or goroutine for the blocks of Go panics.
+	Kind   string       `json:"kind,omitempty"`
+	Header string       `json:"header,omitempty"`
+	Frames []StackFrame `json:"frames,omitempty"`
+}
+
+type StackFrame struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Library  bool   `json:"library,omitempty"`
+}
+
+// MapStackTrace parses a stack trace, returning nil when it has no frames.
+func MapStackTrace(raw string) *StackTrace {
+	if raw == "" {
+		return nil
+	}
+	trace, ok := stacktrace.Parse(raw)
+	if !ok {
+		return nil
+	}
+	r := &StackTrace{
+		Language: string(trace.Language),
+		Blocks:   make([]StackBlock, len(trace.Blocks)),
+	}
+	for i, block := range trace.Blocks {
+		r.Blocks[i] = StackBlock{
+			Kind:   string(block.Kind),
+			Header: block.Header,
+		}
+		for _, frame := range block.Frames {
+			r.Blocks[i].Frames = append(r.Blocks[i].Frames, StackFrame(frame))
+		}
+	}
+	return r
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jamillosantos/lovr/internal/service/processors"
+)
+
+type IndexStatsResponse struct {
+	Pending   int        `json:"pending"`
+	LagMs     int64      `json:"lagMs"`
+	Indexed   uint64     `json:"indexed"`
+	LastFlush *time.Time `json:"lastFlush,omitempty"`
+}
+
+func MapIndexStatsResponse(stats processors.IndexerStats) IndexStatsResponse {
+	r := IndexStatsResponse{
+		Pending: stats.Pending,
+		LagMs:   stats.Lag.Milliseconds(),
+		Indexed: stats.Indexed,
+	}
+	if !stats.LastFlush.IsZero() {
+		r.LastFlush = &stats.LastFlush
+	}
+	return r
+}
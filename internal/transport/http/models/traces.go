@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+)
+
+type TraceSpan struct {
+	SpanID       string      `json:"spanId"`
+	ParentSpanID string      `json:"parentSpanId,omitempty"`
+	Start        time.Time   `json:"start"`
+	End          time.Time   `json:"end"`
+	EntryIDs     []string    `json:"entryIds"`
+	Children     []TraceSpan `json:"children"`
+}
+
+type TraceResponse struct {
+	TraceID   string      `json:"traceId"`
+	Start     time.Time   `json:"start"`
+	End       time.Time   `json:"end"`
+	Entries   []Entry     `json:"entries"`
+	Spans     []TraceSpan `json:"spans"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+func MapTraceResponse(response entryreader.TraceResponse) TraceResponse {
+	return TraceResponse{
+		TraceID:   response.TraceID,
+		Start:     response.Start,
+		End:       response.End,
+		Entries:   DomainToLogEntries(response.Entries),
+		Spans:     mapTraceSpans(response.Spans),
+		Truncated: response.Truncated,
+	}
+}
+
+func mapTraceSpans(spans []*entryreader.TraceSpan) []TraceSpan {
+	r := make([]TraceSpan, len(spans))
+	for i, span := range spans {
+		ids := make([]string, len(span.Entries))
+		for j, e := range span.Entries {
+			ids[j] = e.ID
+		}
+		r[i] = TraceSpan{
+			SpanID:       span.SpanID,
+			ParentSpanID: span.ParentSpanID,
+			Start:        span.Start,
+			End:          span.End,
+			EntryIDs:     ids,
+			Children:     mapTraceSpans(span.Children),
+		}
+	}
+	return r
+}
@@ -17,6 +17,21 @@ func WithWC(wc *sync.WaitGroup) Option {
 	}
 }
 
+// WithIndexStats makes the API report the indexing lag.
+func WithIndexStats(indexStats IndexStats) Option {
+	return func(api *API) {
+		api.indexStats = indexStats
+	}
+}
+
+// WithRejects makes the API list the entries that could not be parsed or
+// processed.
+func WithRejects(rejects Rejects) Option {
+	return func(api *API) {
+		api.rejects = rejects
+	}
+}
+
 // WithUI makes the API serve the given filesystem as the web interface.
 func WithUI(uiFS fs.FS) Option {
 	return func(api *API) {
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/jamillosantos/lovr/internal/transport/http/models"
+)
+
+// EntriesQueryExplain returns how the q query is parsed, with the hits of
+// each clause. Syntax errors are reported in the body, with a 200, so
+// editors can validate queries as they are typed.
+func (api *API) EntriesQueryExplain(fctx fiber.Ctx) error {
+	e, err := api.reader.Explain(fctx.Context(), fctx.Query("q", ""))
+	if err != nil {
+		return err
+	}
+	return fctx.JSON(models.MapQueryExplainResponse(e))
+}
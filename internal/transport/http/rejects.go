@@ -0,0 +1,23 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/jamillosantos/lovr/internal/deadletter"
+	"github.com/jamillosantos/lovr/internal/transport/http/models"
+)
+
+// Rejects reports the entries that could not be parsed or processed
+// (deadletter.Sink).
+type Rejects interface {
+	Count() uint64
+	Recent() []deadletter.Reject
+}
+
+// EntriesRejects lists the most recent rejected entries, newest first.
+func (api *API) EntriesRejects(fctx fiber.Ctx) error {
+	if api.rejects == nil {
+		return fctx.JSON(models.RejectsResponse{Rejects: []models.Reject{}})
+	}
+	return fctx.JSON(models.MapRejectsResponse(api.rejects.Count(), api.rejects.Recent()))
+}
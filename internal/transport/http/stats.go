@@ -0,0 +1,23 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/jamillosantos/lovr/internal/service/processors"
+	"github.com/jamillosantos/lovr/internal/transport/http/models"
+)
+
+// IndexStats reports the indexing lag (processors.Indexer).
+type IndexStats interface {
+	Stats() processors.IndexerStats
+}
+
+// EntriesStats reports how many entries are not searchable yet and for how
+// long the oldest of them has been waiting.
+func (api *API) EntriesStats(fctx fiber.Ctx) error {
+	var stats processors.IndexerStats
+	if api.indexStats != nil {
+		stats = api.indexStats.Stats()
+	}
+	return fctx.JSON(models.MapIndexStatsResponse(stats))
+}
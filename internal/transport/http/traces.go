@@ -0,0 +1,23 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/jamillosantos/lovr/internal/service/entryreader"
+	"github.com/jamillosantos/lovr/internal/transport/http/models"
+)
+
+// Trace returns the entries of a trace, oldest first, with their span tree.
+func (api *API) Trace(fctx fiber.Ctx) error {
+	res, err := api.reader.Trace(fctx.Context(), fctx.Params("id"))
+	if errors.Is(err, entryreader.ErrTraceNotFound) {
+		return fctx.Status(http.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return err
+	}
+	return fctx.JSON(models.MapTraceResponse(res))
+}
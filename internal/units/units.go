@@ -0,0 +1,92 @@
+// Package units parses the quantities logged as strings: numbers ("503"),
+// durations ("152ms", "1h30m") and byte sizes ("1.2MB", "512 KiB"). It is
+// shared by the indexer and the query parser so both normalize values the
+// same way.
+package units
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the dimension of a quantity.
+type Kind string
+
+const (
+	// Number is a plain number.
+	Number Kind = "number"
+	// Duration is a duration, in nanoseconds.
+	Duration Kind = "duration"
+	// Bytes is a size, in bytes.
+	Bytes Kind = "bytes"
+)
+
+// Quantity is a value normalized to the base unit of its kind.
+type Quantity struct {
+	Value float64
+	Kind  Kind
+}
+
+var (
+	numberPattern = regexp.MustCompile(`^[+-]?(?:\d+(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d+)?$`)
+	unitPattern   = regexp.MustCompile(`^([+-]?(?:\d+(?:\.\d*)?|\.\d+))\s?([a-zA-Zµμ]+)$`)
+)
+
+// durationUnits are the units of time.ParseDuration, case-sensitive as
+// there: 1m is a minute.
+var durationUnits = map[string]float64{
+	"ns": float64(time.Nanosecond),
+	"us": float64(time.Microsecond),
+	"µs": float64(time.Microsecond),
+	"μs": float64(time.Microsecond),
+	"ms": float64(time.Millisecond),
+	"s":  float64(time.Second),
+	"m":  float64(time.Minute),
+	"h":  float64(time.Hour),
+}
+
+// byteUnits are matched case-insensitively: KB, kB and kb are all a
+// thousand bytes, KiB is 1024.
+var byteUnits = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// Parse reads a number, a duration or a byte size. Durations are either a
+// number and a unit, optionally separated by a space (150 ms), or in the
+// compound form of time.ParseDuration (1h30m).
+func Parse(s string) (Quantity, bool) {
+	s = strings.TrimSpace(s)
+	if numberPattern.MatchString(s) {
+		f, err := strconv.ParseFloat(s, 64)
+		return Quantity{Value: f, Kind: Number}, err == nil
+	}
+	if m := unitPattern.FindStringSubmatch(s); m != nil {
+		f, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return Quantity{}, false
+		}
+		if factor, ok := durationUnits[m[2]]; ok {
+			return Quantity{Value: f * factor, Kind: Duration}, true
+		}
+		if factor, ok := byteUnits[strings.ToLower(m[2])]; ok {
+			return Quantity{Value: f * factor, Kind: Bytes}, true
+		}
+		return Quantity{}, false
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return Quantity{Value: float64(d), Kind: Duration}, true
+	}
+	return Quantity{}, false
+}
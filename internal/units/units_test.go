@@ -0,0 +1,45 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Quantity
+	}{
+		{"503", Quantity{503, Number}},
+		{"-1.25", Quantity{-1.25, Number}},
+		{"1e3", Quantity{1000, Number}},
+		{" 42 ", Quantity{42, Number}},
+		{"152ms", Quantity{152e6, Duration}},
+		{"1.5s", Quantity{1.5e9, Duration}},
+		{"150 ms", Quantity{150e6, Duration}},
+		{"250µs", Quantity{250e3, Duration}},
+		{"2m", Quantity{120e9, Duration}},
+		{"1h30m", Quantity{5400e9, Duration}},
+		{"1.2MB", Quantity{1.2e6, Bytes}},
+		{"512 KiB", Quantity{512 * 1024, Bytes}},
+		{"10kb", Quantity{10e3, Bytes}},
+		{"3GiB", Quantity{3 << 30, Bytes}},
+		{"100B", Quantity{100, Bytes}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := Parse(tt.input)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want.Kind, got.Kind)
+			assert.InDelta(t, tt.want.Value, got.Value, 1e-6)
+		})
+	}
+
+	t.Run("should fail on other values", func(t *testing.T) {
+		for _, s := range []string{"", "abc", "NaN", "inf", "0x1F", "1MS", "10 apples", "1.2.3", "v1", "1 000", "1d"} {
+			_, ok := Parse(s)
+			assert.False(t, ok, "%q", s)
+		}
+	})
+}
@@ -24,6 +24,7 @@ const AXIS_WIDTH = 44;
 const BAR_GAP = 2;
 
 const LEVEL_COLORS: Record<string, string> = {
+	trace: "var(--chart-level-debug)",
 	debug: "var(--chart-level-debug)",
 	info: "var(--chart-level-info)",
 	warning: "var(--chart-level-warning)",
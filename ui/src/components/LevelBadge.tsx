@@ -4,7 +4,15 @@ import { canonicalLevel } from "@/lib/levels.ts";
 import { useSettings } from "@/lib/settings.tsx";
 import { cn } from "@/lib/utils";
 
-const KNOWN_LEVELS = ["debug", "info", "warning", "error", "fatal", "panic"];
+const KNOWN_LEVELS = [
+	"trace",
+	"debug",
+	"info",
+	"warning",
+	"error",
+	"fatal",
+	"panic",
+];
 
 export function LevelBadge({ level }: { level: Level }) {
 	const { settings } = useSettings();
@@ -1,6 +1,7 @@
 import { Copy, EyeOff, Search, SearchX, X } from "lucide-react";
 import { useEffect, useMemo, useRef, useState } from "react";
 import { LevelBadge } from "@/components/LevelBadge.tsx";
+import { StackTraceView } from "@/components/StackTraceView.tsx";
 import { Button } from "@/components/ui/button";
 import {
 	DropdownMenu,
@@ -210,6 +211,19 @@ function Section({
 	);
 }
 
+// traceFields lists the trace IDs of an entry under their indexed names, so
+// the search actions of FieldRow query them.
+function traceFields(entry: Entry): Field[] {
+	const fields: Field[] = [{ key: "trace_id", value: entry.traceId }];
+	if (entry.spanId) {
+		fields.push({ key: "span_id", value: entry.spanId });
+	}
+	if (entry.parentSpanId) {
+		fields.push({ key: "parent_span_id", value: entry.parentSpanId });
+	}
+	return fields;
+}
+
 const MIN_DETAIL_WIDTH = 320;
 const MAX_DETAIL_WIDTH = 1200;
 
@@ -345,6 +359,20 @@ export function LogDetail({
 						</Section>
 					)}
 
+					{entry.traceId && (
+						<Section title="Trace">
+							<dl className="detail-fields">
+								{traceFields(entry).map((field) => (
+									<FieldRow
+										field={field}
+										key={field.key}
+										onSearchAction={onSearchAction}
+									/>
+								))}
+							</dl>
+						</Section>
+					)}
+
 					{entry.caller && (
 						<Section title="Caller">
 							<p className="detail-caller">{entry.caller}</p>
@@ -353,7 +381,26 @@ export function LogDetail({
 
 					{entry.stacktrace && (
 						<Section title="Stacktrace">
-							<pre className="detail-stacktrace">{entry.stacktrace}</pre>
+							{entry.stack ? (
+								<StackTraceView stack={entry.stack} />
+							) : (
+								<pre className="detail-stacktrace">{entry.stacktrace}</pre>
+							)}
+						</Section>
+					)}
+
+					{entry.raw && (
+						<Section title="Raw line">
+							<Button
+								variant="outline"
+								size="sm"
+								className="detail-raw-copy"
+								onClick={() => navigator.clipboard.writeText(entry.raw ?? "")}
+							>
+								<Copy />
+								Copy raw line
+							</Button>
+							<pre className="detail-stacktrace">{entry.raw}</pre>
 						</Section>
 					)}
 				</div>
@@ -1,16 +1,16 @@
-import { Search, X } from "lucide-react";
+import { Search, TriangleAlert, X } from "lucide-react";
 import { useEffect, useRef, useState } from "react";
 import { SearchHelp } from "@/components/SearchHelp.tsx";
 import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
-import { fetchFields, fetchFieldValues } from "@/lib/api.ts";
+import { fetchFieldsInfo, fetchFieldValues } from "@/lib/api.ts";
 import { lastToken, replaceLastToken, splitToken } from "@/lib/autocomplete.ts";
 import {
 	activeTermIndexes,
 	matchParen,
 	tokenizeForHighlight,
 } from "@/lib/highlight.ts";
-import { fieldTerm } from "@/lib/query.ts";
+import { conflictingFields, fieldTerm } from "@/lib/query.ts";
 import { cn } from "@/lib/utils";
 
 const MAX_SUGGESTIONS = 8;
@@ -35,6 +35,7 @@ export function SearchBar({
 	onClear: () => void;
 }) {
 	const [fields, setFields] = useState<string[]>([]);
+	const [types, setTypes] = useState<Record<string, string[]>>({});
 	const [suggestions, setSuggestions] = useState<Suggestion[]>([]);
 	const [active, setActive] = useState(0);
 	const [open, setOpen] = useState(false);
@@ -46,8 +47,11 @@ export function SearchBar({
 
 	useEffect(() => {
 		const abort = new AbortController();
-		fetchFields(abort.signal)
-			.then(setFields)
+		fetchFieldsInfo(abort.signal)
+			.then((info) => {
+				setFields(info.fields);
+				setTypes(info.types);
+			})
 			.catch(() => {});
 		return () => abort.abort();
 	}, []);
@@ -104,6 +108,8 @@ export function SearchBar({
 		};
 	}, [value, fields]);
 
+	const conflicts = conflictingFields(value, types);
+
 	const apply = (suggestion: Suggestion) => {
 		onChange(replaceLastToken(value, suggestion.insert));
 		if (suggestion.kind === "value") {
@@ -122,7 +128,14 @@ export function SearchBar({
 		>
 			<div className="search-field">
 				<Search className="search-field-icon" />
-				<div aria-hidden className="search-highlight" ref={highlightRef}>
+				<div
+					aria-hidden
+					className={cn(
+						"search-highlight",
+						conflicts.length > 0 && "search-field-conflicts",
+					)}
+					ref={highlightRef}
+				>
 					{(() => {
 						const tokens = tokenizeForHighlight(value);
 						const termIndexes =
@@ -154,7 +167,10 @@ export function SearchBar({
 					})()}
 				</div>
 				<Input
-					className="search-field-input search-field-input-highlighted"
+					className={cn(
+						"search-field-input search-field-input-highlighted",
+						conflicts.length > 0 && "search-field-conflicts",
+					)}
 					placeholder="Search… (e.g. timeout, level:error, nested.host:db1)"
 					value={value}
 					onChange={(event) => onChange(event.target.value)}
@@ -214,6 +230,21 @@ export function SearchBar({
 						<X />
 					</Button>
 				)}
+				{conflicts.length > 0 && (
+					<span
+						className="search-conflicts"
+						role="img"
+						aria-label="Fields logged with different types"
+						title={conflicts
+							.map(
+								(field) =>
+									`${field} was logged as ${types[field]?.join(" and ")}: comparisons skip the values they cannot compare.`,
+							)
+							.join("\n")}
+					>
+						<TriangleAlert className="size-4" />
+					</span>
+				)}
 				<SearchHelp />
 				{open && (
 					<ul className="search-suggestions">
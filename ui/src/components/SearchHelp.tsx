@@ -11,9 +11,16 @@ const examples: [string, string][] = [
 	["level:error", "match a field"],
 	["nested.host:db1", "nested fields use dots"],
 	['message:"failed to process"', "exact phrase"],
+	["request_id:=AbC-123", "whole value, exactly as logged"],
+	["message:~Error", "case-sensitive words"],
 	["-level:debug", "exclude matches"],
 	["status:>499", "numeric ranges (>, >=, <, <=)"],
+	["latency:>100ms", "durations and sizes (size:>1MB), whatever the unit"],
+	["status:[500 TO 599]", "inclusive [ ] or exclusive { } ranges, * is open"],
+	["timestamp:>now-15m", "times relative to now (ms, s, m, h, d, w)"],
+	["since:1h", "entries of the last hour (timestamp:>=now-1h)"],
 	["message:*onnect*", "wildcards match inside words"],
+	["message:/time(out|d)/", "regular expressions match whole words"],
 	["_exists_:user_id", "entries having a key (alias for user_id:*)"],
 	["level:error OR level:fatal", "OR combines alternatives (uppercase)"],
 	[
@@ -21,6 +28,8 @@ const examples: [string, string][] = [
 		"parentheses group for precedence",
 	],
 	["level:(error OR fatal)", 'value lists match any item ("in")'],
+	["NOT level:debug AND timeout", "AND is optional; NOT excludes a term"],
+	["-(level:debug OR level:trace)", "exclude a whole group"],
 ];
 
 export function SearchHelp() {
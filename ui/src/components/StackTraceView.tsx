@@ -0,0 +1,71 @@
+import { ChevronRight } from "lucide-react";
+import { useState } from "react";
+import type { StackFrame, StackTrace } from "@/domain/models.ts";
+import { frameLocation, groupFrames } from "@/lib/stack.ts";
+import { cn } from "@/lib/utils";
+
+function Frame({ frame }: { frame: StackFrame }) {
+	return (
+		<div className={cn("stack-frame", frame.library && "stack-frame-library")}>
+			{frame.function && (
+				<span className="stack-frame-function">{frame.function} </span>
+			)}
+			<span className="stack-frame-location">{frameLocation(frame)}</span>
+			{frame.code && <div className="stack-frame-code">{frame.code}</div>}
+		</div>
+	);
+}
+
+// FoldedFrames shows a run of library frames as a count until expanded.
+function FoldedFrames({ frames }: { frames: StackFrame[] }) {
+	const [expanded, setExpanded] = useState(false);
+	if (expanded) {
+		return frames.map((frame, i) => (
+			// biome-ignore lint/suspicious/noArrayIndexKey: frames repeat in recursions
+			<Frame frame={frame} key={i} />
+		));
+	}
+	return (
+		<button
+			type="button"
+			className="stack-folded"
+			onClick={() => setExpanded(true)}
+		>
+			<ChevronRight />
+			{frames.length} library {frames.length === 1 ? "frame" : "frames"}
+		</button>
+	);
+}
+
+// StackTraceView highlights the functions and locations of a parsed stack
+// trace, folding the frames of the standard library and dependencies.
+export function StackTraceView({ stack }: { stack: StackTrace }) {
+	return (
+		<div className="detail-stacktrace">
+			{stack.blocks.map((block, i) => (
+				// biome-ignore lint/suspicious/noArrayIndexKey: blocks have no identity
+				<div className="stack-block" key={i}>
+					{block.header && (
+						<div
+							className={cn(
+								"stack-header",
+								block.kind === "panic" && "stack-header-panic",
+							)}
+						>
+							{block.header}
+						</div>
+					)}
+					{groupFrames(block.frames ?? []).map((group, j) =>
+						group.kind === "frame" ? (
+							// biome-ignore lint/suspicious/noArrayIndexKey: frames repeat in recursions
+							<Frame frame={group.frame} key={j} />
+						) : (
+							// biome-ignore lint/suspicious/noArrayIndexKey: frames repeat in recursions
+							<FoldedFrames frames={group.frames} key={j} />
+						),
+					)}
+				</div>
+			))}
+		</div>
+	);
+}
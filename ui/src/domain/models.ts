@@ -1,4 +1,5 @@
 export type Level =
+	| "trace"
 	| "debug"
 	| "info"
 	| "warning"
@@ -20,6 +21,36 @@ export interface Entry {
 	fields?: Field[];
 	caller?: string;
 	stacktrace?: string;
+	/** The stack trace split into frames, when it could be parsed. */
+	stack?: StackTrace;
+	/** The line exactly as read from the source. */
+	raw?: string;
+	traceId?: string;
+	spanId?: string;
+	parentSpanId?: string;
+}
+
+export interface StackFrame {
+	function?: string;
+	file: string;
+	line?: number;
+	column?: number;
+	/** The source line Python prints under the frame. */
+	code?: string;
+	/** Standard library, vendored packages, Go modules or node_modules. */
+	library?: boolean;
+}
+
+export interface StackBlock {
+	/** Set for the blocks of Go panics. */
+	kind?: "panic" | "goroutine";
+	header?: string;
+	frames?: StackFrame[];
+}
+
+export interface StackTrace {
+	language: "go" | "java" | "python" | "node";
+	blocks: StackBlock[];
 }
 
 export interface SearchResponse {
@@ -94,13 +94,28 @@ export async function fetchHistogram(
 	return res.json();
 }
 
-export async function fetchFields(signal?: AbortSignal): Promise<string[]> {
+export interface FieldsInfo {
+	fields: string[];
+	/** The types each field was indexed as: string, number, boolean or date. */
+	types: Record<string, string[]>;
+}
+
+export async function fetchFieldsInfo(
+	signal?: AbortSignal,
+): Promise<FieldsInfo> {
 	const res = await fetch(`${API_BASE}/entries/fields`, { signal });
 	if (!res.ok) {
 		throw new Error(`fetching fields failed: ${res.status}`);
 	}
-	const body: { fields: string[] | null } = await res.json();
-	return body.fields ?? [];
+	const body: {
+		fields: string[] | null;
+		types: Record<string, string[]> | null;
+	} = await res.json();
+	return { fields: body.fields ?? [], types: body.types ?? {} };
+}
+
+export async function fetchFields(signal?: AbortSignal): Promise<string[]> {
+	return (await fetchFieldsInfo(signal)).fields;
 }
 
 export async function fetchFieldValues(
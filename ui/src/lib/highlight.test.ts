@@ -34,6 +34,50 @@ describe("tokenizeForHighlight", () => {
 		]);
 	});
 
+	test("AND, NOT and negated groups", () => {
+		expect(types("NOT a AND -(b)")).toEqual([
+			"operator:NOT",
+			"ws: ",
+			"value:a",
+			"ws: ",
+			"operator:AND",
+			"ws: ",
+			"modifier:-",
+			"paren-0:(",
+			"value:b",
+			"paren-0:)",
+		]);
+	});
+
+	test("regular expressions stay whole", () => {
+		expect(types("-message:/timeout (after|in) \\d+ms/ x")).toEqual([
+			"modifier:-",
+			"key:message",
+			"colon::",
+			"regexp:/timeout (after|in) \\d+ms/",
+			"ws: ",
+			"value:x",
+		]);
+	});
+
+	test("ranges stay whole", () => {
+		expect(types("timestamp:[now-1h TO now} x")).toEqual([
+			"key:timestamp",
+			"colon::",
+			"value:[now-1h TO now}",
+			"ws: ",
+			"value:x",
+		]);
+	});
+
+	test("paths are not regular expressions", () => {
+		expect(types("route:/api/v1/login")).toEqual([
+			"key:route",
+			"colon::",
+			"value:/api/v1/login",
+		]);
+	});
+
 	test("quoted phrases stay whole", () => {
 		expect(types('message:"a (OR) b"')).toEqual([
 			"key:message",
@@ -8,6 +8,7 @@ export interface HighlightToken {
 		| "colon"
 		| "value"
 		| "phrase"
+		| "regexp"
 		| "operator"
 		| "modifier"
 		| `paren-${number}`;
@@ -72,7 +73,14 @@ export function activeTermIndexes(
 	tokens: HighlightToken[],
 	caret: number,
 ): Set<number> {
-	const partTypes = new Set(["modifier", "key", "colon", "value", "phrase"]);
+	const partTypes = new Set([
+		"modifier",
+		"key",
+		"colon",
+		"value",
+		"phrase",
+		"regexp",
+	]);
 	const isParen = (i: number) =>
 		i >= 0 &&
 		i < tokens.length &&
@@ -188,7 +196,7 @@ export function activeTermIndexes(
 	let hasKey = false;
 	for (let i = start; i <= end; i++) {
 		const type = (tokens[i] as HighlightToken).type;
-		if (type === "value" || type === "phrase") {
+		if (type === "value" || type === "phrase" || type === "regexp") {
 			group.add(i);
 		}
 		if (type === "key") {
@@ -227,6 +235,50 @@ function insideQuotes(input: string, index: number): boolean {
 	return inQuotes;
 }
 
+// rangeEnd returns the offset of the bracket closing the range opened at
+// start, or -1.
+function rangeEnd(input: string, start: number): number {
+	for (let i = start + 1; i < input.length; i++) {
+		if (input[i] === "]" || input[i] === "}") {
+			return i;
+		}
+	}
+	return -1;
+}
+
+// regexpEnd returns the offset of the slash closing the regular expression
+// opened at start, or -1 when there is none or it does not end the word
+// (route:/api/v1/login is a path). Mirrors the server tokenizer.
+function regexpEnd(input: string, start: number): number {
+	for (let i = start + 1; i < input.length; i++) {
+		const ch = input[i];
+		if (ch === "\\") {
+			i++;
+			continue;
+		}
+		if (ch === "/") {
+			if (i === start + 1) {
+				return -1;
+			}
+			const next = input[i + 1];
+			if (
+				next === undefined ||
+				next === " " ||
+				next === "\t" ||
+				next === ")"
+			) {
+				return i;
+			}
+			return -1;
+		}
+	}
+	return -1;
+}
+
+function isRegexp(value: string): boolean {
+	return value.length > 2 && value.startsWith("/") && value.endsWith("/");
+}
+
 export function tokenizeForHighlight(input: string): HighlightToken[] {
 	const tokens: HighlightToken[] = [];
 	let depth = 0;
@@ -283,15 +335,32 @@ export function tokenizeForHighlight(input: string): HighlightToken[] {
 			continue;
 		}
 
-		// A word: up to whitespace, parenthesis or quote.
+		// A word: up to whitespace, parenthesis or quote, except inside a
+		// /regular expression/ or a [from TO to] range starting its value.
 		let j = i;
 		while (j < input.length && !' \t()"'.includes(input[j] as string)) {
+			const sofar = input.slice(i, j);
+			const startsValue =
+				sofar === "" ||
+				sofar === "+" ||
+				sofar === "-" ||
+				sofar.endsWith(":");
+			let end = -1;
+			if (startsValue && input[j] === "/") {
+				end = regexpEnd(input, j);
+			} else if (startsValue && (input[j] === "[" || input[j] === "{")) {
+				end = rangeEnd(input, j);
+			}
+			if (end > 0) {
+				j = end + 1;
+				break;
+			}
 			j++;
 		}
 		const word = input.slice(i, j);
 		i = j;
 
-		if (word === "OR") {
+		if (word === "OR" || word === "AND" || word === "NOT") {
 			push(word, "operator");
 			continue;
 		}
@@ -302,14 +371,13 @@ export function tokenizeForHighlight(input: string): HighlightToken[] {
 			rest = rest.slice(1);
 		}
 
-		const colon = rest.indexOf(":");
+		const colon = rest.startsWith("/") ? -1 : rest.indexOf(":");
 		if (colon > 0) {
 			push(rest.slice(0, colon), "key");
 			push(":", "colon");
-			push(rest.slice(colon + 1), "value");
-		} else {
-			push(rest, "value");
+			rest = rest.slice(colon + 1);
 		}
+		push(rest, isRegexp(rest) ? "regexp" : "value");
 	}
 
 	return tokens;
@@ -0,0 +1,28 @@
+import { describe, expect, test } from "bun:test";
+import { conflictingFields } from "./query.ts";
+
+describe("conflictingFields", () => {
+	const types = {
+		status: ["number", "string"],
+		level: ["string"],
+		created: ["date", "string"],
+	};
+
+	test("lists the fields with more than one type", () => {
+		expect(
+			conflictingFields("status:>=500 level:error -created:<now", types),
+		).toEqual(["status", "created"]);
+	});
+
+	test("lists each field once", () => {
+		expect(
+			conflictingFields("status:500 OR status:(502 OR 503)", types),
+		).toEqual(["status"]);
+	});
+
+	test("ignores values and unknown fields", () => {
+		expect(conflictingFields("status message:status other:x", types)).toEqual(
+			[],
+		);
+	});
+});
@@ -1,3 +1,5 @@
+import { tokenizeForHighlight } from "@/lib/highlight.ts";
+
 // Builds a fielded term for the bluge query string syntax, quoting values
 // that would otherwise break tokenization.
 export function fieldTerm(key: string, value: unknown): string {
@@ -58,3 +60,18 @@ export function stateURL(pathname: string, state: URLState): string {
 	const encoded = params.toString();
 	return encoded ? `${pathname}?${encoded}` : pathname;
 }
+
+// Returns the fields of the query that were indexed with more than one type
+// (status as 503 and "N/A"): comparisons skip the values of the other types.
+export function conflictingFields(
+	query: string,
+	types: Record<string, string[]>,
+): string[] {
+	const found = new Set<string>();
+	for (const token of tokenizeForHighlight(query)) {
+		if (token.type === "key" && (types[token.text]?.length ?? 0) > 1) {
+			found.add(token.text);
+		}
+	}
+	return [...found];
+}
@@ -0,0 +1,25 @@
+import { describe, expect, test } from "bun:test";
+import { frameLocation, groupFrames } from "./stack.ts";
+
+describe("groupFrames", () => {
+	test("gathers the consecutive library frames", () => {
+		const app = { function: "main.main", file: "/app/main.go" };
+		const std = { function: "runtime.main", file: "proc.go", library: true };
+		const dep = { function: "chi.ServeHTTP", file: "mux.go", library: true };
+		expect(groupFrames([app, std, dep, app, std])).toEqual([
+			{ kind: "frame", frame: app },
+			{ kind: "library", frames: [std, dep] },
+			{ kind: "frame", frame: app },
+			{ kind: "library", frames: [std] },
+		]);
+	});
+});
+
+describe("frameLocation", () => {
+	test("appends the line and column when known", () => {
+		expect(frameLocation({ file: "/app/index.js", line: 3, column: 9 })).toBe(
+			"/app/index.js:3:9",
+		);
+		expect(frameLocation({ file: "Native Method" })).toBe("Native Method");
+	});
+});
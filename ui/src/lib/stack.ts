@@ -0,0 +1,34 @@
+import type { StackFrame } from "@/domain/models.ts";
+
+export type FrameGroup =
+	| { kind: "frame"; frame: StackFrame }
+	| { kind: "library"; frames: StackFrame[] };
+
+// groupFrames gathers the consecutive library frames, so they can be folded
+// into a single line.
+export function groupFrames(frames: StackFrame[]): FrameGroup[] {
+	const groups: FrameGroup[] = [];
+	for (const frame of frames) {
+		const last = groups[groups.length - 1];
+		if (!frame.library) {
+			groups.push({ kind: "frame", frame });
+		} else if (last?.kind === "library") {
+			last.frames.push(frame);
+		} else {
+			groups.push({ kind: "library", frames: [frame] });
+		}
+	}
+	return groups;
+}
+
+// frameLocation renders the file:line:column of a frame.
+export function frameLocation(frame: StackFrame): string {
+	let location = frame.file;
+	if (frame.line) {
+		location += `:${frame.line}`;
+	}
+	if (frame.column) {
+		location += `:${frame.column}`;
+	}
+	return location;
+}